@@ -0,0 +1,184 @@
+package achievements
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+const (
+	stateFile = "achievements.json"
+	dayFormat = "2006-01-02"
+)
+
+var (
+	Now = time.Now
+)
+
+// Push is a successful push, counted towards achievements.
+type Push struct {
+	Time   time.Time
+	Repo   string
+	Branch string
+	// Event is the name of the push event kind, if it is known.
+	Event string
+}
+
+// Earned is an achievement earned by a push.
+type Earned struct {
+	Name   string    `json:"name"`
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo,omitempty"`
+	Branch string    `json:"branch,omitempty"`
+}
+
+// State is the push counters achievements are worked out from, and the achievements earned so far.
+type State struct {
+	// Pushes counts the pushes in each repository.
+	Pushes map[string]int `json:"pushes"`
+	// LastDay is the day of the latest push, Streak how many days in a row up to it had a push.
+	LastDay string   `json:"last-day,omitempty"`
+	Streak  int      `json:"streak"`
+	Earned  []Earned `json:"earned"`
+}
+
+// Achievement is a milestone a push can reach.
+type Achievement struct {
+	Name        string
+	Description string
+	// reached checks the push against the counters, which already include it.  firstToday is true when this is
+	// the first push of its day.
+	reached func(p Push, state *State, firstToday bool) bool
+}
+
+// All lists the achievements, most notable first, a push earning several celebrates the first of them.
+func All() []Achievement {
+	return []Achievement{
+		{
+			Name:        "century",
+			Description: "every 100th push in a repository",
+			reached: func(p Push, state *State, firstToday bool) bool {
+				return state.Pushes[p.Repo]%100 == 0
+			},
+		},
+		{
+			Name:        "week-streak",
+			Description: "pushing 7 days in a row",
+			reached: func(p Push, state *State, firstToday bool) bool {
+				return firstToday && state.Streak%7 == 0
+			},
+		},
+		{
+			Name:        "new-branch",
+			Description: "the first push of a new branch",
+			reached: func(p Push, state *State, firstToday bool) bool {
+				return p.Event == push.NewBranch.Name()
+			},
+		},
+		{
+			Name:        "night-owl",
+			Description: "pushing at 3am",
+			reached: func(p Push, state *State, firstToday bool) bool {
+				return p.Time.Hour() == 3
+			},
+		},
+		{
+			Name:        "first-of-the-day",
+			Description: "the first push of the day",
+			reached: func(p Push, state *State, firstToday bool) bool {
+				return firstToday
+			},
+		},
+	}
+}
+
+// Find looks up an achievement by name.
+func Find(name string) (Achievement, bool) {
+	for _, achievement := range All() {
+		if achievement.Name == name {
+			return achievement, true
+		}
+	}
+	return Achievement{}, false
+}
+
+func statePath() (string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, stateFile), nil
+}
+
+// Load reads the achievement counters, missing counters are all zero.
+func Load() (*State, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	return load(path)
+}
+
+func load(path string) (*State, error) {
+	saved := &State{}
+	if err := state.Load(path, saved); err != nil {
+		return nil, err
+	}
+	if saved.Pushes == nil {
+		saved.Pushes = map[string]int{}
+	}
+	return saved, nil
+}
+
+// count adds the push to the counters and returns the achievements it earned.
+func (s *State) count(p Push) []Achievement {
+	s.Pushes[p.Repo]++
+	today := p.Time.Format(dayFormat)
+	firstToday := s.LastDay != today
+	if firstToday {
+		if s.LastDay == p.Time.AddDate(0, 0, -1).Format(dayFormat) {
+			s.Streak++
+		} else {
+			s.Streak = 1
+		}
+		s.LastDay = today
+	}
+	earned := []Achievement{}
+	for _, achievement := range All() {
+		if achievement.reached(p, s, firstToday) {
+			earned = append(earned, achievement)
+			s.Earned = append(s.Earned, Earned{Name: achievement.Name, Time: p.Time, Repo: p.Repo, Branch: p.Branch})
+		}
+	}
+	return earned
+}
+
+// Record counts a successful push and returns the achievements it earned, most notable first.  Pushes in two
+// repositories at the same time are counted one after the other.
+func Record(p Push) ([]Achievement, error) {
+	if p.Time.IsZero() {
+		p.Time = Now()
+	}
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("unable to create the achievements directory: %s", err.Error())
+	}
+	unlock, err := state.Lock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	saved, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	earned := saved.count(p)
+	return earned, state.Save(path, saved)
+}
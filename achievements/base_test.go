@@ -0,0 +1,72 @@
+package achievements
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+func names(earned []Achievement) string {
+	result := []string{}
+	for _, achievement := range earned {
+		result = append(result, achievement.Name)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestRecordEarnsAchievements(t *testing.T) {
+	statetest.UseTemp(t)
+	noon := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		Push     Push
+		Expected string
+	}{
+		{Push{Time: noon, Repo: "/src/one"}, "first-of-the-day"},
+		{Push{Time: noon.Add(time.Minute), Repo: "/src/one"}, ""},
+		{Push{Time: noon.Add(2 * time.Minute), Repo: "/src/two", Event: "new-branch"}, "new-branch"},
+		{Push{Time: noon.Add(15 * time.Hour), Repo: "/src/one"}, "night-owl,first-of-the-day"},
+	}
+	for _, c := range cases {
+		earned, err := Record(c.Push)
+		if err != nil {
+			t.Fatalf("Unable to record push: %s", err.Error())
+		}
+		if names(earned) != c.Expected {
+			t.Errorf("Pushing %+v should earn '%s', earned '%s'", c.Push, c.Expected, names(earned))
+		}
+	}
+	state, _ := Load()
+	if state.Pushes["/src/one"] != 3 || state.Pushes["/src/two"] != 1 || state.Streak != 2 || len(state.Earned) != 4 {
+		t.Errorf("The counters should be saved, were %+v", state)
+	}
+}
+
+func TestCountMilestones(t *testing.T) {
+	noon := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	state := &State{Pushes: map[string]int{"/src/one": 99}, LastDay: "2024-03-09", Streak: 6}
+	if earned := state.count(Push{Time: noon, Repo: "/src/one"}); names(earned) != "century,week-streak,first-of-the-day" {
+		t.Errorf("The 100th push on the 7th day in a row should earn both, earned '%s'", names(earned))
+	}
+	state = &State{Pushes: map[string]int{}, LastDay: "2024-03-08", Streak: 6}
+	if state.count(Push{Time: noon}); state.Streak != 1 {
+		t.Errorf("Missing a day should start the streak over, was %d", state.Streak)
+	}
+}
+
+func TestAchievementsCommand(t *testing.T) {
+	statetest.UseTemp(t)
+	Record(Push{Time: time.Date(2024, 3, 10, 3, 30, 0, 0, time.Local), Repo: "/src/one"})
+	var out bytes.Buffer
+	app := &cli.App{Writer: &out, Commands: []*cli.Command{AchievementsCommand}}
+	if err := app.Run([]string{"push-sounds", "achievements"}); err != nil {
+		t.Fatalf("Listing achievements should not return an error: %s", err.Error())
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != len(All())+3 || !strings.Contains(out.String(), "2024-03-10 03:30") || !strings.HasPrefix(lines[2], "century                 0 never") {
+		t.Errorf("Every achievement should be listed with when it was earned, was:\n%s", out.String())
+	}
+}
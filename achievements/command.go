@@ -0,0 +1,35 @@
+package achievements
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+var AchievementsCommand = &cli.Command{
+	Name:   "achievements",
+	Usage:  "List the push milestones you have earned, and the ones still to earn",
+	Action: listAchievements,
+}
+
+func listAchievements(c *cli.Context) error {
+	state, err := Load()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "%-18s %6s %-16s %s\n", "Name", "Earned", "Last", "Description")
+	fmt.Fprintf(c.App.Writer, "%s %s %s %s\n", strings.Repeat("-", 18), strings.Repeat("-", 6), strings.Repeat("-", 16), strings.Repeat("-", len("Description")))
+	for _, achievement := range All() {
+		times := 0
+		last := "never"
+		for _, earned := range state.Earned {
+			if earned.Name == achievement.Name {
+				times++
+				last = earned.Time.Local().Format("2006-01-02 15:04")
+			}
+		}
+		fmt.Fprintf(c.App.Writer, "%-18s %6d %-16s %s\n", achievement.Name, times, last, achievement.Description)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package burst
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+const (
+	stateFile  = "burst.json"
+	timeFormat = "15:04:05"
+)
+
+// State is what the pushes on this machine have played lately, shared by every hook so they agree on it.
+type State struct {
+	// LastPlayed is when the last sound started playing.
+	LastPlayed time.Time `json:"last-played,omitempty"`
+	// WindowStart is when the push that opened the coalesce window arrived, it is zero when there is none.
+	WindowStart time.Time `json:"window-start,omitempty"`
+	// Pushes counts the pushes in the coalesce window, including the one that opened it.
+	Pushes int `json:"pushes,omitempty"`
+}
+
+// Decision is whether a push plays a sound, or why it doesn't.
+type Decision struct {
+	Play bool
+	// Pushes is how many pushes are in the coalesce window when the push plays for all of them.
+	Pushes int
+	// Silenced is why the push doesn't play.
+	Silenced string
+}
+
+func statePath() (string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, stateFile), nil
+}
+
+// update changes the state while holding its lock, so pushes from several hooks at once take turns.
+func update(change func(*State)) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to create the state directory: %s", err.Error())
+	}
+	unlock, err := state.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	saved := State{}
+	if err := state.Load(path, &saved); err != nil {
+		return err
+	}
+	change(&saved)
+	return state.Save(path, saved)
+}
+
+// Arrive records a push arriving at now.  With a coalesce window, the push that opens it plays, and a push
+// during it is counted and doesn't play, unless it is the many'th push which plays for all of them, a many of 0
+// never does.  A push that isn't coalesced doesn't play within cooldown of the last sound.
+func Arrive(now time.Time, cooldown time.Duration, window time.Duration, many int) (Decision, error) {
+	decision := Decision{}
+	err := update(func(state *State) {
+		if window > 0 && !state.WindowStart.IsZero() && now.Before(state.WindowStart.Add(window)) {
+			state.Pushes++
+			if many > 1 && state.Pushes == many {
+				decision = Decision{Play: true, Pushes: state.Pushes}
+				state.LastPlayed = now
+				return
+			}
+			decision = Decision{Silenced: fmt.Sprintf("coalesced with the push at %s", state.WindowStart.Format(timeFormat))}
+			return
+		}
+		if cooldown > 0 && now.Before(state.LastPlayed.Add(cooldown)) {
+			decision = Decision{Silenced: fmt.Sprintf("cooldown after the sound at %s", state.LastPlayed.Format(timeFormat))}
+			return
+		}
+		decision = Decision{Play: true, Pushes: 1}
+		state.LastPlayed = now
+		state.WindowStart = time.Time{}
+		state.Pushes = 0
+		if window > 0 {
+			state.WindowStart = now
+			state.Pushes = 1
+		}
+	})
+	return decision, err
+}
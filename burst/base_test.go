@@ -0,0 +1,58 @@
+package burst
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+)
+
+var start = time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+
+func TestArriveCooldown(t *testing.T) {
+	statetest.UseTemp(t)
+	cases := []struct {
+		After time.Duration
+		Play  bool
+	}{
+		{0, true},
+		{2 * time.Second, false},
+		{9 * time.Second, false},
+		{10 * time.Second, true},
+		{15 * time.Second, false},
+	}
+	for _, c := range cases {
+		decision, err := Arrive(start.Add(c.After), 10*time.Second, 0, 0)
+		if err != nil {
+			t.Fatalf("Arriving should not return an error: %s", err.Error())
+		}
+		if decision.Play != c.Play || (!c.Play && decision.Silenced == "") {
+			t.Errorf("A push %s after the first should play %t, decided %+v", c.After, c.Play, decision)
+		}
+	}
+}
+
+func TestArriveCoalesce(t *testing.T) {
+	statetest.UseTemp(t)
+	cases := []struct {
+		After  time.Duration
+		Play   bool
+		Pushes int
+	}{
+		{0, true, 1},
+		{time.Second, false, 0},
+		{2 * time.Second, true, 3},
+		{3 * time.Second, false, 0},
+		{5 * time.Second, true, 1},
+		{6 * time.Second, false, 0},
+	}
+	for _, c := range cases {
+		decision, err := Arrive(start.Add(c.After), 0, 5*time.Second, 3)
+		if err != nil {
+			t.Fatalf("Arriving should not return an error: %s", err.Error())
+		}
+		if decision.Play != c.Play || decision.Pushes != c.Pushes || (!c.Play && decision.Silenced == "") {
+			t.Errorf("A push %s after the first should play %t for %d pushes, decided %+v", c.After, c.Play, c.Pushes, decision)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/rules"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	Load = load
+)
+
+type Config struct {
+	LibraryBase      string   `yaml:"library-base,omitempty"`
+	Libraries        []string `yaml:"libraries,omitempty"`
+	FailureLibraries []string `yaml:"failure-libraries,omitempty"`
+	SuccessLibraries []string `yaml:"success-libraries,omitempty"`
+	Achievements     []string `yaml:"achievements,omitempty"`
+	Strategy         string   `yaml:"strategy,omitempty"`
+	Selection        string   `yaml:"selection,omitempty"`
+	Seed             string   `yaml:"seed,omitempty"`
+	SeedFrom         string   `yaml:"seed-from,omitempty"`
+	RecentPlays      string   `yaml:"recent-plays,omitempty"`
+	RecentHours      string   `yaml:"recent-hours,omitempty"`
+	// Enabled, Volume and the other numbers and booleans are kept as written, so they can be layered and reported the same way as other settings.
+	Enabled string `yaml:"enabled,omitempty"`
+	Volume  string `yaml:"volume,omitempty"`
+	// QuietHours are ranges of the time of day, like 22:00..07:00, when sounds are silent or come from the
+	// quiet libraries.
+	QuietHours     []string `yaml:"quiet-hours,omitempty"`
+	QuietLibraries []string `yaml:"quiet-libraries,omitempty"`
+	QuietVolume    string   `yaml:"quiet-volume,omitempty"`
+	// Cooldown and Coalesce are durations, like 10s, that keep bursts of pushes from playing a sound each.
+	Cooldown          string   `yaml:"cooldown,omitempty"`
+	Coalesce          string   `yaml:"coalesce,omitempty"`
+	CoalescePushes    string   `yaml:"coalesce-pushes,omitempty"`
+	CoalesceLibraries []string `yaml:"coalesce-libraries,omitempty"`
+	// Playback is what to do when another sound is already playing, waiting at most PlaybackWait.
+	Playback     string `yaml:"playback,omitempty"`
+	PlaybackWait string `yaml:"playback-wait,omitempty"`
+	// MaxDuration is how long a sound plays before it fades out, Timeout how long play may take altogether.
+	MaxDuration string `yaml:"max-duration,omitempty"`
+	Timeout     string `yaml:"timeout,omitempty"`
+	// Events maps the name of a push event kind to the libraries to pull a sound from for that kind of push.
+	Events map[string][]string `yaml:"events,omitempty"`
+	// Rules are checked in order before anything else, the first one matching a push decides its libraries.
+	Rules []rules.Rule `yaml:"rules,omitempty"`
+	// Profile is the name of the profile in use.
+	Profile string `yaml:"profile,omitempty"`
+	// Profiles are named sets of settings, used in place of the config's own settings when chosen.
+	Profiles map[string]*Config `yaml:"profiles,omitempty"`
+}
+
+// load reads the config file at path.  A missing file is the same as an empty config.
+func load(path string) (*Config, error) {
+	config := &Config{}
+	if path == "" {
+		return config, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("unable to read config %s: %s", path, err.Error())
+	}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return &Config{}, fmt.Errorf("unable to parse config %s: %s", path, err.Error())
+	}
+	if err := config.validate(); err != nil {
+		return &Config{}, fmt.Errorf("invalid config %s: %s", path, err.Error())
+	}
+	for name, profile := range config.Profiles {
+		if profile == nil {
+			config.Profiles[name] = &Config{}
+			continue
+		}
+		if profile.Profile != "" || len(profile.Profiles) > 0 {
+			return &Config{}, fmt.Errorf("invalid profile %s in config %s: profiles can't choose or hold other profiles", name, path)
+		}
+		if err := profile.validate(); err != nil {
+			return &Config{}, fmt.Errorf("invalid profile %s in config %s: %s", name, path, err.Error())
+		}
+	}
+	return config, nil
+}
+
+func (c *Config) validate() error {
+	for name := range c.Events {
+		if _, err := push.ParseEventKind(name); err != nil {
+			return fmt.Errorf("invalid events: %s", err.Error())
+		}
+	}
+	for _, key := range Keys() {
+		if key.check == nil {
+			continue
+		}
+		for _, value := range key.Get(c) {
+			if err := key.check(key.Name, value); err != nil {
+				return err
+			}
+		}
+	}
+	for i, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid %s: %s", rule.Describe(i), err.Error())
+		}
+	}
+	return nil
+}
+
+// Save writes the config to path, creating its directory if needed.
+func (c *Config) Save(path string) error {
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("unable to write config %s: %s", path, err.Error())
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to create config directory for %s: %s", path, err.Error())
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("unable to write config %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+// EventLibraries returns the libraries configured for a kind of push, or nil if there aren't any.
+func (c *Config) EventLibraries(kind push.EventKind) []string {
+	libraries := c.Events[kind.Name()]
+	if len(libraries) == 0 {
+		return nil
+	}
+	return libraries
+}
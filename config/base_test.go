@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/push"
+)
+
+func writeTempConfig(t *testing.T, content string) (string, func()) {
+	dir, err := os.MkdirTemp("", "temp-config-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	path := filepath.Join(dir, "push-sounds.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unable to write config: %s", err.Error())
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadEvents(t *testing.T) {
+	path, cleanup := writeTempConfig(t, "events:\n  tag: [fanfare]\n  force:\n    - alarm\n    - sirens\n")
+	defer cleanup()
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Error loading config: %s", err.Error())
+	}
+	if !reflect.DeepEqual(config.EventLibraries(push.TagPush), []string{"fanfare"}) {
+		t.Errorf("Tag pushes should use 'fanfare', was: %#v", config.EventLibraries(push.TagPush))
+	}
+	if !reflect.DeepEqual(config.EventLibraries(push.ForcePush), []string{"alarm", "sirens"}) {
+		t.Errorf("Force pushes should use 'alarm' and 'sirens', was: %#v", config.EventLibraries(push.ForcePush))
+	}
+	if config.EventLibraries(push.BranchUpdate) != nil {
+		t.Errorf("Branch updates aren't configured, but returned: %#v", config.EventLibraries(push.BranchUpdate))
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	config, err := Load(filepath.Join(os.TempDir(), "does-not-exist", "push-sounds.yaml"))
+	if err != nil {
+		t.Fatalf("A missing config file should not be an error: %s", err.Error())
+	}
+	if len(config.Events) != 0 {
+		t.Errorf("A missing config file should be empty, was: %#v", config)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	invalid := []string{
+		"events: [",
+		"events:\n  bogus: [alarm]\n",
+		"volume: loud\n",
+		"profiles:\n  work:\n    profile: home\n",
+		"profiles:\n  work:\n    enabled: maybe\n",
+	}
+	for _, content := range invalid {
+		path, cleanup := writeTempConfig(t, content)
+		_, err := Load(path)
+		cleanup()
+		if err == nil {
+			t.Errorf("Loading invalid config %#v should have returned an error", content)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path, cleanup := writeTempConfig(t, "rules:\n  - name: mirror\n    remote: mirror\n    mute: true\n  - branch: [main, release/*]\n    libraries: [fanfare]\n")
+	defer cleanup()
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Error loading config: %s", err.Error())
+	}
+	if len(config.Rules) != 2 {
+		t.Fatalf("Expected two rules, was: %#v", config.Rules)
+	}
+	if !config.Rules[0].Mute || config.Rules[0].Name != "mirror" {
+		t.Errorf("First rule should mute the mirror remote, was: %#v", config.Rules[0])
+	}
+	if len(config.Rules[1].Branch) != 2 {
+		t.Errorf("Second rule should have two branch patterns, was: %#v", config.Rules[1].Branch)
+	}
+}
+
+func TestLoadInvalidRule(t *testing.T) {
+	path, cleanup := writeTempConfig(t, "rules:\n  - branch: main\n")
+	defer cleanup()
+	if _, err := Load(path); err == nil {
+		t.Errorf("A rule without libraries or mute should not load")
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+var ConfigCommand = &cli.Command{
+	Name:  "config",
+	Usage: "show and change push-sounds settings",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "get",
+			Usage:     "Show the effective value of a setting and where it came from",
+			ArgsUsage: "<key>",
+			Action:    getSetting,
+		},
+		{
+			Name:      "set",
+			Usage:     "Change a setting in the user config, or the repo config with --repo (no values removes it)",
+			ArgsUsage: "<key> [values...]",
+			Action:    setSetting,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "repo",
+					Usage: "change the repo config (" + RepoConfigName + ") instead of the user config",
+				},
+			},
+		},
+		{
+			Name:   "list",
+			Usage:  "Show the effective value of every setting and where it came from",
+			Action: listSettings,
+		},
+		{
+			Name:   "path",
+			Usage:  "Show the config files that apply to the current repository",
+			Action: configPaths,
+		},
+	},
+}
+
+func describeSource(value Value) string {
+	if value.Origin == "" {
+		return value.Source.Name()
+	}
+	return fmt.Sprintf("%s (%s)", value.Source.Name(), value.Origin)
+}
+
+func getSetting(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("required the key of one setting")
+	}
+	key, err := LookupKey(c.Args().First())
+	if err != nil {
+		return err
+	}
+	settings, err := LoadSettings(c)
+	if err != nil {
+		return err
+	}
+	value := settings.lookup(c, key)
+	fmt.Fprintln(c.App.Writer, strings.Join(value.Values, ","))
+	fmt.Fprintf(c.App.ErrWriter, "from %s\n", describeSource(value))
+	return nil
+}
+
+func setSetting(c *cli.Context) error {
+	if c.Args().Len() == 0 {
+		return fmt.Errorf("required the key of the setting to change")
+	}
+	key, err := LookupKey(c.Args().First())
+	if err != nil {
+		return err
+	}
+	path := c.String("config")
+	if c.Bool("repo") {
+		if path, err = repoConfigForWriting(); err != nil {
+			return err
+		}
+	}
+	config, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := key.Set(config, c.Args().Tail()); err != nil {
+		return err
+	}
+	if err := config.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Set %s in %s\n", key.Name, path)
+	return nil
+}
+
+// repoConfigForWriting is the repo config that applies now, or a new one at the top of the repository.
+func repoConfigForWriting() (string, error) {
+	if path := RepoConfigPath(); path != "" {
+		return path, nil
+	}
+	topLevel, err := GetTopLevel()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the repository for its config: %s", err.Error())
+	}
+	return filepath.Join(topLevel, RepoConfigName), nil
+}
+
+func listSettings(c *cli.Context) error {
+	settings, err := LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	fmt.Fprintf(c.App.Writer, "%-22s %-30s %s\n", "Key", "Value", "Source")
+	fmt.Fprintf(c.App.Writer, "%s %s %s\n", strings.Repeat("-", 22), strings.Repeat("-", 30), strings.Repeat("-", len("Source")))
+	for _, key := range Keys() {
+		value := settings.lookup(c, key)
+		if len(value.Values) == 0 {
+			continue
+		}
+		fmt.Fprintf(c.App.Writer, "%-22s %-30s %s\n", key.Name, strings.Join(value.Values, ","), describeSource(value))
+	}
+	for _, layer := range []Layer{settings.Repo, settings.User} {
+		if len(layer.Config.Rules) > 0 {
+			fmt.Fprintf(c.App.Writer, "%-22s %-30d %s\n", "rules", len(layer.Config.Rules), describeSource(Value{Source: layer.Source, Origin: layer.Path}))
+		}
+	}
+	return nil
+}
+
+func configPaths(c *cli.Context) error {
+	for _, layer := range []Layer{{Source: UserSource, Path: c.String("config")}, {Source: RepoSource, Path: RepoConfigPath()}} {
+		switch {
+		case layer.Path == "":
+			fmt.Fprintf(c.App.Writer, "%-12s none\n", layer.Source.Name())
+		case exists(layer.Path):
+			fmt.Fprintf(c.App.Writer, "%-12s %s\n", layer.Source.Name(), layer.Path)
+		default:
+			fmt.Fprintf(c.App.Writer, "%-12s %s (does not exist)\n", layer.Source.Name(), layer.Path)
+		}
+	}
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
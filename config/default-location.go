@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+var (
+	GetUserConfigBase = os.UserConfigDir
+)
+
+// UserConfigPath is where the push-sounds file or directory called name goes in the user config directory, or in
+// the current directory when there isn't one.
+func UserConfigPath(name string) string {
+	configDir, err := GetUserConfigBase()
+	if err != nil {
+		configDir = "."
+	}
+	return filepath.Join(configDir, name)
+}
+
+func GetLocationDefault() string {
+	return UserConfigPath("push-sounds.yaml")
+}
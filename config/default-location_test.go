@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocationDefault(t *testing.T) {
+	orig := GetUserConfigBase
+	defer func() { GetUserConfigBase = orig }()
+
+	GetUserConfigBase = func() (string, error) {
+		return "base", nil
+	}
+	expected := filepath.Join("base", "push-sounds.yaml")
+	if actual := GetLocationDefault(); actual != expected {
+		t.Errorf("Expected default config location to be '%s', was '%s'", expected, actual)
+	}
+
+	GetUserConfigBase = func() (string, error) {
+		return "something ridiculous", fmt.Errorf("error getting base config path")
+	}
+	expected = filepath.Join(".", "push-sounds.yaml")
+	if actual := GetLocationDefault(); actual != expected {
+		t.Errorf("Expected default config location to be '%s', was '%s'", expected, actual)
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jasoncorbett/push-sounds/git"
+)
+
+const (
+	// GitPrefix starts the git config keys for push-sounds settings, e.g. push-sounds.libraries.
+	GitPrefix = "push-sounds."
+)
+
+var (
+	GetGitConfig = gitConfig
+)
+
+// gitConfig reads the push-sounds settings from the effective git config.  List settings can be given as several
+// entries or separated by commas, for everything else the last entry wins like it does for git.
+func gitConfig() (*Config, error) {
+	config := &Config{}
+	output, err := git.Run("config", "--get-regexp", `^`+strings.ReplaceAll(GitPrefix, ".", `\.`))
+	var gitErr *git.Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		// git config exits with 1 when nothing matches
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	values := map[string][]string{}
+	keys := []Key{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, " ", 2)
+		// a key without a value is true, as git treats it for booleans
+		value := "true"
+		if len(parts) == 2 {
+			value = parts[1]
+		}
+		key, err := LookupKey(strings.TrimPrefix(parts[0], GitPrefix))
+		if err != nil {
+			continue
+		}
+		if _, seen := values[key.Name]; !seen {
+			keys = append(keys, key)
+		}
+		if key.List {
+			values[key.Name] = append(values[key.Name], key.parseEnv(value)...)
+		} else {
+			values[key.Name] = []string{value}
+		}
+	}
+	for _, key := range keys {
+		if err := key.Set(config, values[key.Name]); err != nil {
+			return &Config{}, err
+		}
+	}
+	return config, nil
+}
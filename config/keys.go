@@ -0,0 +1,418 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/playback"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/rules"
+	"github.com/jasoncorbett/push-sounds/selection"
+)
+
+const (
+	// FullVolume plays sounds as loud as they were recorded.
+	FullVolume = 100
+	MaxVolume  = 200
+)
+
+// Key is a single setting that can come from a flag, an environment variable, or a config file.
+type Key struct {
+	Name string
+	// Env is the environment variable for the setting, lists are separated by commas.
+	Env string
+	// List is true for settings that hold more than one value.
+	List  bool
+	Usage string
+	// Default is used when there is no flag for the setting to take its default from.
+	Default []string
+	get     func(*Config) []string
+	set     func(*Config, []string)
+	// check validates a value before it is set, if the setting needs it.
+	check func(name string, value string) error
+}
+
+func (k Key) Get(config *Config) []string {
+	return k.get(config)
+}
+
+func (k Key) Set(config *Config, values []string) error {
+	if !k.List && len(values) > 1 {
+		return fmt.Errorf("%s only takes one value", k.Name)
+	}
+	if k.check != nil {
+		for _, value := range values {
+			if err := k.check(k.Name, value); err != nil {
+				return err
+			}
+		}
+	}
+	k.set(config, values)
+	return nil
+}
+
+func parseBool(name string, value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s should be true or false, was '%s'", name, value)
+	}
+}
+
+func parseInt(name string, value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s should be a whole number, was '%s'", name, value)
+	}
+	return number, nil
+}
+
+func parseDuration(name string, value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%s should be a duration like 10s or 1m, was '%s'", name, value)
+	}
+	return duration, nil
+}
+
+func checkBool(name string, value string) error {
+	_, err := parseBool(name, value)
+	return err
+}
+
+func checkVolume(name string, value string) error {
+	volume, err := parseInt(name, value)
+	if err != nil {
+		return err
+	}
+	if volume < 0 || volume > MaxVolume {
+		return fmt.Errorf("%s should be between 0 and %d, was %d", name, MaxVolume, volume)
+	}
+	return nil
+}
+
+func checkDuration(name string, value string) error {
+	_, err := parseDuration(name, value)
+	return err
+}
+
+// checkCount checks a value is a whole number that isn't negative.
+func checkCount(name string, value string) error {
+	count, err := parseInt(name, value)
+	if err != nil {
+		return err
+	}
+	if count < 0 {
+		return fmt.Errorf("%s can't be negative, was %d", name, count)
+	}
+	return nil
+}
+
+// checkTimeRange checks a value is a range of the time of day, like 22:00..07:00.
+func checkTimeRange(name string, value string) error {
+	if err := (rules.Times{value}).Validate(); err != nil {
+		return fmt.Errorf("%s: %s", name, err.Error())
+	}
+	return nil
+}
+
+// oneOf checks a value is one of the choices.
+func oneOf(choices ...string) func(name string, value string) error {
+	return func(name string, value string) error {
+		for _, choice := range choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s should be one of %s, was '%s'", name, strings.Join(choices, ", "), value)
+	}
+}
+
+func single(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func eventKey(kind push.EventKind) Key {
+	return Key{
+		Name:  "events." + kind.Name(),
+		List:  true,
+		Usage: fmt.Sprintf("libraries to pull a sound from for %s pushes", kind.Name()),
+		get: func(c *Config) []string {
+			return c.Events[kind.Name()]
+		},
+		set: func(c *Config, values []string) {
+			if c.Events == nil {
+				c.Events = map[string][]string{}
+			}
+			if len(values) == 0 {
+				delete(c.Events, kind.Name())
+			} else {
+				c.Events[kind.Name()] = values
+			}
+		},
+	}
+}
+
+// Keys lists every setting, in the order they are shown.
+func Keys() []Key {
+	keys := []Key{
+		{
+			Name:  "profile",
+			Env:   "PUSH_SOUNDS_PROFILE",
+			Usage: "the profile to take settings from",
+			get:   func(c *Config) []string { return single(c.Profile) },
+			set:   func(c *Config, values []string) { c.Profile = first(values) },
+		},
+		{
+			Name:  "library-base",
+			Env:   "PUSH_SOUNDS_LIBRARY",
+			Usage: "the base directory for libraries",
+			get:   func(c *Config) []string { return single(c.LibraryBase) },
+			set:   func(c *Config, values []string) { c.LibraryBase = first(values) },
+		},
+		{
+			Name:    "libraries",
+			Env:     "PUSH_SOUNDS_LIBRARIES",
+			List:    true,
+			Usage:   "libraries to pull a sound from, as name or name:weight",
+			Default: []string{"default"},
+			get:     func(c *Config) []string { return c.Libraries },
+			set:     func(c *Config, values []string) { c.Libraries = values },
+		},
+		{
+			Name:    "failure-libraries",
+			Env:     "PUSH_SOUNDS_FAILURE_LIBRARIES",
+			List:    true,
+			Usage:   "libraries to pull a sound from when a chained hook fails",
+			Default: []string{"failure"},
+			get:     func(c *Config) []string { return c.FailureLibraries },
+			set:     func(c *Config, values []string) { c.FailureLibraries = values },
+		},
+		{
+			Name:    "success-libraries",
+			Env:     "PUSH_SOUNDS_SUCCESS_LIBRARIES",
+			List:    true,
+			Usage:   "libraries to pull a sound from when a push made with push-sounds git succeeds",
+			Default: []string{"success"},
+			get:     func(c *Config) []string { return c.SuccessLibraries },
+			set:     func(c *Config, values []string) { c.SuccessLibraries = values },
+		},
+		{
+			Name:    "achievements",
+			Env:     "PUSH_SOUNDS_ACHIEVEMENTS",
+			List:    true,
+			Usage:   "libraries to pull a sound from when a push earns an achievement",
+			Default: []string{"achievements"},
+			get:     func(c *Config) []string { return c.Achievements },
+			set:     func(c *Config, values []string) { c.Achievements = values },
+		},
+		{
+			Name:    "strategy",
+			Env:     "PUSH_SOUNDS_STRATEGY",
+			Usage:   "how sounds are picked: " + strings.Join(selection.Strategies(), ", "),
+			Default: []string{selection.WeightedStrategy},
+			get:     func(c *Config) []string { return single(c.Strategy) },
+			set:     func(c *Config, values []string) { c.Strategy = first(values) },
+			check:   oneOf(selection.Strategies()...),
+		},
+		{
+			Name:  "seed",
+			Env:   "PUSH_SOUNDS_SEED",
+			Usage: "always make the same choices for the same seed",
+			get:   func(c *Config) []string { return single(c.Seed) },
+			set:   func(c *Config, values []string) { c.Seed = first(values) },
+		},
+		{
+			Name:  "seed-from",
+			Env:   "PUSH_SOUNDS_SEED_FROM",
+			Usage: "seed the choices from the pushed commit, branch or repo",
+			get:   func(c *Config) []string { return single(c.SeedFrom) },
+			set:   func(c *Config, values []string) { c.SeedFrom = first(values) },
+			check: oneOf("commit", "branch", "repo"),
+		},
+		{
+			Name:    "selection",
+			Env:     "PUSH_SOUNDS_SELECTION",
+			Usage:   "for the weighted strategy, library picks a library by weight then a file in it, file pools the files of every library",
+			Default: []string{selection.ByLibrary.Name()},
+			get:     func(c *Config) []string { return single(c.Selection) },
+			set:     func(c *Config, values []string) { c.Selection = first(values) },
+			check:   oneOf(selection.ByLibrary.Name(), selection.ByFile.Name()),
+		},
+		{
+			Name:    "recent-plays",
+			Env:     "PUSH_SOUNDS_RECENT_PLAYS",
+			Usage:   "for the not-recently-played strategy, avoid the files played in this many of the latest plays",
+			Default: []string{"5"},
+			get:     func(c *Config) []string { return single(c.RecentPlays) },
+			set:     func(c *Config, values []string) { c.RecentPlays = first(values) },
+			check:   checkCount,
+		},
+		{
+			Name:    "recent-hours",
+			Env:     "PUSH_SOUNDS_RECENT_HOURS",
+			Usage:   "for the not-recently-played strategy, avoid the files played in this many hours",
+			Default: []string{"0"},
+			get:     func(c *Config) []string { return single(c.RecentHours) },
+			set:     func(c *Config, values []string) { c.RecentHours = first(values) },
+			check:   checkCount,
+		},
+		{
+			Name:    "enabled",
+			Env:     "PUSH_SOUNDS_ENABLED",
+			Usage:   "whether to play sounds at all",
+			Default: []string{"true"},
+			get:     func(c *Config) []string { return single(c.Enabled) },
+			set:     func(c *Config, values []string) { c.Enabled = first(values) },
+			check:   checkBool,
+		},
+		{
+			Name:    "volume",
+			Env:     "PUSH_SOUNDS_VOLUME",
+			Usage:   fmt.Sprintf("how loud to play sounds, as a percentage up to %d", MaxVolume),
+			Default: []string{strconv.Itoa(FullVolume)},
+			get:     func(c *Config) []string { return single(c.Volume) },
+			set:     func(c *Config, values []string) { c.Volume = first(values) },
+			check:   checkVolume,
+		},
+		{
+			Name:  "quiet-hours",
+			Env:   "PUSH_SOUNDS_QUIET_HOURS",
+			List:  true,
+			Usage: "times of day when sounds are silent or come from the quiet libraries, like 22:00..07:00",
+			get:   func(c *Config) []string { return c.QuietHours },
+			set:   func(c *Config, values []string) { c.QuietHours = values },
+			check: checkTimeRange,
+		},
+		{
+			Name:  "quiet-libraries",
+			Env:   "PUSH_SOUNDS_QUIET_LIBRARIES",
+			List:  true,
+			Usage: "libraries to pull a sound from in quiet hours, there is no sound without them",
+			get:   func(c *Config) []string { return c.QuietLibraries },
+			set:   func(c *Config, values []string) { c.QuietLibraries = values },
+		},
+		{
+			Name:    "quiet-volume",
+			Env:     "PUSH_SOUNDS_QUIET_VOLUME",
+			Usage:   "how loud to play sounds from the quiet libraries, as a percentage",
+			Default: []string{"30"},
+			get:     func(c *Config) []string { return single(c.QuietVolume) },
+			set:     func(c *Config, values []string) { c.QuietVolume = first(values) },
+			check:   checkVolume,
+		},
+		{
+			Name:    "cooldown",
+			Env:     "PUSH_SOUNDS_COOLDOWN",
+			Usage:   "after a sound plays, don't play another on this machine for this long, like 10s",
+			Default: []string{"0s"},
+			get:     func(c *Config) []string { return single(c.Cooldown) },
+			set:     func(c *Config, values []string) { c.Cooldown = first(values) },
+			check:   checkDuration,
+		},
+		{
+			Name:    "coalesce",
+			Env:     "PUSH_SOUNDS_COALESCE",
+			Usage:   "play for the first push only, the pushes within this long of it, like 5s, are coalesced into its sound",
+			Default: []string{"0s"},
+			get:     func(c *Config) []string { return single(c.Coalesce) },
+			set:     func(c *Config, values []string) { c.Coalesce = first(values) },
+			check:   checkDuration,
+		},
+		{
+			Name:    "coalesce-pushes",
+			Env:     "PUSH_SOUNDS_COALESCE_PUSHES",
+			Usage:   "the push that makes this many coalesced pushes plays from the coalesce libraries instead, interrupting the first sound if it is still playing",
+			Default: []string{"3"},
+			get:     func(c *Config) []string { return single(c.CoalescePushes) },
+			set:     func(c *Config, values []string) { c.CoalescePushes = first(values) },
+			check:   checkCount,
+		},
+		{
+			Name:  "coalesce-libraries",
+			Env:   "PUSH_SOUNDS_COALESCE_LIBRARIES",
+			List:  true,
+			Usage: "libraries to pull a sound from for many coalesced pushes, like a bigger fanfare",
+			get:   func(c *Config) []string { return c.CoalesceLibraries },
+			set:   func(c *Config, values []string) { c.CoalesceLibraries = values },
+		},
+		{
+			Name:    "playback",
+			Env:     "PUSH_SOUNDS_PLAYBACK",
+			Usage:   "when another sound is playing, queue waits for it, drop plays nothing and interrupt stops it",
+			Default: []string{playback.Queue},
+			get:     func(c *Config) []string { return single(c.Playback) },
+			set:     func(c *Config, values []string) { c.Playback = first(values) },
+			check:   oneOf(playback.Policies()...),
+		},
+		{
+			Name:    "playback-wait",
+			Env:     "PUSH_SOUNDS_PLAYBACK_WAIT",
+			Usage:   "the longest to wait for another sound to finish or be interrupted before giving up, by default 1s for a hook that isn't detached",
+			Default: []string{"10s"},
+			get:     func(c *Config) []string { return single(c.PlaybackWait) },
+			set:     func(c *Config, values []string) { c.PlaybackWait = first(values) },
+			check:   checkDuration,
+		},
+		{
+			Name:    "max-duration",
+			Env:     "PUSH_SOUNDS_MAX_DURATION",
+			Usage:   "fade out sounds that are still playing after this long, 0s plays them to the end",
+			Default: []string{"5s"},
+			get:     func(c *Config) []string { return single(c.MaxDuration) },
+			set:     func(c *Config, values []string) { c.MaxDuration = first(values) },
+			check:   checkDuration,
+		},
+		{
+			Name:    "timeout",
+			Env:     "PUSH_SOUNDS_TIMEOUT",
+			Usage:   "give up on playing a sound that takes longer than this altogether, 0s waits for it",
+			Default: []string{"0s"},
+			get:     func(c *Config) []string { return single(c.Timeout) },
+			set:     func(c *Config, values []string) { c.Timeout = first(values) },
+			check:   checkDuration,
+		},
+	}
+	for _, kind := range push.EventKinds() {
+		keys = append(keys, eventKey(kind))
+	}
+	return keys
+}
+
+func LookupKey(name string) (Key, error) {
+	for _, key := range Keys() {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown setting '%s'", name)
+}
+
+// parseEnv splits an environment variable value into the values for a key.
+func (k Key) parseEnv(value string) []string {
+	if !k.List {
+		return []string{value}
+	}
+	values := []string{}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var ProfileCommand = &cli.Command{
+	Name:  "profile",
+	Usage: "switch between named sets of settings",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List the profiles in the user and repo configs, marking the one in use",
+			Action: listProfiles,
+		},
+		{
+			Name:      "use",
+			Usage:     "Use a profile from now on, saving it in the user config (no name stops using a profile)",
+			ArgsUsage: "[profile name]",
+			Action:    useProfile,
+		},
+		{
+			Name:      "show",
+			Usage:     "Show the settings in a profile, the one in use when no name is given",
+			ArgsUsage: "[profile name]",
+			Action:    showProfile,
+		},
+	},
+}
+
+func listProfiles(c *cli.Context) error {
+	settings, err := LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	names := settings.ProfileNames()
+	if len(names) == 0 {
+		fmt.Fprintln(c.App.Writer, "No profiles found")
+		return nil
+	}
+	current := settings.Lookup(c, "profile")
+	for _, name := range names {
+		profile, _ := settings.FindProfile(name)
+		marker := " "
+		if name == current.String() {
+			marker = "*"
+		}
+		fmt.Fprintf(c.App.Writer, "%s %-15s %s\n", marker, name, profile.Path)
+	}
+	if current.String() != "" {
+		fmt.Fprintf(c.App.Writer, "\nusing %s from %s\n", current.String(), describeSource(current))
+	}
+	return nil
+}
+
+func useProfile(c *cli.Context) error {
+	if c.Args().Len() > 1 {
+		return fmt.Errorf("required at most one profile name")
+	}
+	name := c.Args().First()
+	settings, err := LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	if _, found := settings.FindProfile(name); name != "" && !found {
+		return fmt.Errorf("unknown profile '%s'", name)
+	}
+	path := c.String("config")
+	config, err := Load(path)
+	if err != nil {
+		return err
+	}
+	config.Profile = name
+	if err := config.Save(path); err != nil {
+		return err
+	}
+	if name == "" {
+		fmt.Fprintln(c.App.Writer, "Stopped using a profile")
+	} else {
+		fmt.Fprintf(c.App.Writer, "Using profile %s\n", name)
+	}
+	// a profile chosen some other way still wins over the user config
+	if current := settings.Lookup(c, "profile"); current.Source > UserSource && current.String() != name {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: profile %s from %s is used instead\n", current.String(), describeSource(current))
+	}
+	return nil
+}
+
+func showProfile(c *cli.Context) error {
+	if c.Args().Len() > 1 {
+		return fmt.Errorf("required at most one profile name")
+	}
+	settings, err := LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	name := c.Args().First()
+	if name == "" {
+		name = settings.Lookup(c, "profile").String()
+	}
+	if name == "" {
+		return fmt.Errorf("not using a profile, required a profile name")
+	}
+	profile, found := settings.FindProfile(name)
+	if !found {
+		return fmt.Errorf("unknown profile '%s'", name)
+	}
+	content, err := yaml.Marshal(profile.Config)
+	if err != nil {
+		return fmt.Errorf("unable to show profile %s: %s", name, err.Error())
+	}
+	fmt.Fprintf(c.App.Writer, "# profile %s from %s\n%s", name, profile.Path, content)
+	return nil
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/git"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	RepoConfigName = ".push-sounds.yaml"
+)
+
+var (
+	GetTopLevel = git.TopLevel
+	Getwd       = os.Getwd
+)
+
+// Source is where the value of a setting came from, in increasing order of precedence, except that a profile
+// chosen with the profile flag or environment variable wins over the repo config and git config.
+type Source int
+
+const (
+	DefaultSource Source = iota
+	UserSource
+	ProfileSource
+	RepoSource
+	GitSource
+	EnvSource
+	FlagSource
+)
+
+func (s Source) Name() string {
+	switch s {
+	case DefaultSource:
+		return "default"
+	case UserSource:
+		return "user config"
+	case ProfileSource:
+		return "profile"
+	case RepoSource:
+		return "repo config"
+	case GitSource:
+		return "git config"
+	case EnvSource:
+		return "environment"
+	case FlagSource:
+		return "flag"
+	default:
+		return "unknown"
+	}
+}
+
+// Layer is a config file, a profile, or the git config, and the source it provides values for.
+type Layer struct {
+	Source Source
+	Path   string
+	// Name is the name of the profile for a profile layer.
+	Name   string
+	Config *Config
+}
+
+// origin names where the value of key is set in the layer.
+func (l Layer) origin(key Key) string {
+	switch l.Source {
+	case GitSource:
+		return GitPrefix + key.Name
+	case ProfileSource:
+		return fmt.Sprintf("%s in %s", l.Name, l.Path)
+	default:
+		return l.Path
+	}
+}
+
+// Settings are the config files, profile and git config that apply to the current repository.
+type Settings struct {
+	User    Layer
+	Profile Layer
+	Repo    Layer
+	Git     Layer
+	// ProfileChosen is true when the profile was chosen with the profile flag or environment variable rather than
+	// the user config, its settings then win over the repo config and git config too.
+	ProfileChosen bool
+}
+
+// layers are the config layers in order of precedence.
+func (s *Settings) layers() []Layer {
+	if s.ProfileChosen {
+		return []Layer{s.Profile, s.Git, s.Repo, s.User}
+	}
+	return []Layer{s.Git, s.Repo, s.Profile, s.User}
+}
+
+// Value is the effective value of a setting.
+type Value struct {
+	Key    Key
+	Values []string
+	Source Source
+	// Origin is the file, environment variable or flag the value came from.
+	Origin string
+}
+
+func (v Value) String() string {
+	return first(v.Values)
+}
+
+// Bool is the value of a boolean setting, accepting the same spellings as git.
+func (v Value) Bool() (bool, error) {
+	return parseBool(v.Key.Name, v.String())
+}
+
+// Int is the value of a whole number setting.
+func (v Value) Int() (int, error) {
+	return parseInt(v.Key.Name, v.String())
+}
+
+// Duration is the value of a duration setting, like 10s.
+func (v Value) Duration() (time.Duration, error) {
+	return parseDuration(v.Key.Name, v.String())
+}
+
+// RepoConfigPath finds the nearest repo config file, walking up from the current directory to the top of the
+// repository.  It returns an empty string when there isn't one, or when not in a repository.
+func RepoConfigPath() string {
+	topLevel, err := GetTopLevel()
+	if err != nil {
+		return ""
+	}
+	dir, err := Getwd()
+	if err != nil {
+		return ""
+	}
+	topLevel = filepath.Clean(topLevel)
+	for {
+		path := filepath.Join(dir, RepoConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if filepath.Clean(dir) == topLevel || parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadSettings reads the user config named by the config flag, the repo config for the current repository, and
+// the push-sounds settings in git config.
+func LoadSettings(c *cli.Context) (*Settings, error) {
+	settings := &Settings{
+		User: Layer{Source: UserSource, Path: c.String("config"), Config: &Config{}},
+		Repo: Layer{Source: RepoSource, Path: RepoConfigPath(), Config: &Config{}},
+		Git:  Layer{Source: GitSource, Config: &Config{}},
+		// the profile is only known once the other layers are loaded
+		Profile: Layer{Source: ProfileSource, Config: &Config{}},
+	}
+	var loadErr error
+	for _, layer := range []*Layer{&settings.User, &settings.Repo} {
+		config, err := Load(layer.Path)
+		if err != nil && loadErr == nil {
+			// a broken config file is left out, so the other settings still apply
+			loadErr = err
+		}
+		layer.Config = config
+	}
+	gitConfig, err := GetGitConfig()
+	if err != nil && loadErr == nil {
+		loadErr = fmt.Errorf("unable to read git config: %s", err.Error())
+	}
+	settings.Git.Config = gitConfig
+	if chosen := settings.Lookup(c, "profile"); chosen.String() != "" {
+		profile, found := settings.FindProfile(chosen.String())
+		if !found && loadErr == nil {
+			loadErr = fmt.Errorf("unknown profile '%s'", chosen.String())
+		}
+		settings.Profile = profile
+		settings.ProfileChosen = chosen.Source == FlagSource || chosen.Source == EnvSource
+	}
+	return settings, loadErr
+}
+
+// Lookup finds the effective value of a setting: a flag that was set, then the environment, then the config
+// layers in the order of layers, and finally the flag's default value.
+func (s *Settings) Lookup(c *cli.Context, name string) Value {
+	key, err := LookupKey(name)
+	if err != nil {
+		// not a setting, so it only ever comes from its flag
+		key = Key{Name: name, List: c.StringSlice(name) != nil}
+	}
+	return s.lookup(c, key)
+}
+
+func (s *Settings) lookup(c *cli.Context, key Key) Value {
+	if c.IsSet(key.Name) {
+		return Value{Key: key, Values: flagValues(c, key), Source: FlagSource, Origin: "--" + key.Name}
+	}
+	if value, found := os.LookupEnv(key.Env); found && key.Env != "" {
+		return Value{Key: key, Values: key.parseEnv(value), Source: EnvSource, Origin: key.Env}
+	}
+	if key.get != nil {
+		for _, layer := range s.layers() {
+			if values := key.Get(layer.Config); len(values) > 0 {
+				return Value{Key: key, Values: values, Source: layer.Source, Origin: layer.origin(key)}
+			}
+		}
+	}
+	values := flagValues(c, key)
+	if len(values) == 0 {
+		values = key.Default
+	}
+	return Value{Key: key, Values: values, Source: DefaultSource}
+}
+
+func flagValues(c *cli.Context, key Key) []string {
+	if key.List {
+		return c.StringSlice(key.Name)
+	}
+	return single(c.String(key.Name))
+}
+
+// String is the effective value of a single valued setting.
+func (s *Settings) String(c *cli.Context, name string) string {
+	return s.Lookup(c, name).String()
+}
+
+// StringSlice is the effective value of a list setting.
+func (s *Settings) StringSlice(c *cli.Context, name string) []string {
+	return s.Lookup(c, name).Values
+}
+
+// FindProfile finds a profile by name, in the repo config before the user config.
+func (s *Settings) FindProfile(name string) (Layer, bool) {
+	for _, layer := range []Layer{s.Repo, s.User} {
+		if profile, found := layer.Config.Profiles[name]; found {
+			return Layer{Source: ProfileSource, Path: layer.Path, Name: name, Config: profile}, true
+		}
+	}
+	return Layer{Source: ProfileSource, Name: name, Config: &Config{}}, false
+}
+
+// ProfileNames lists the profiles in the repo and user configs.
+func (s *Settings) ProfileNames() []string {
+	names := []string{}
+	for _, layer := range []Layer{s.Repo, s.User} {
+		for name := range layer.Config.Profiles {
+			if _, found := s.FindProfile(name); found && !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, item string) bool {
+	for _, a := range list {
+		if a == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Config merges the config layers, taking precedence in the order of layers.
+// Repo rules are checked before profile rules, then user rules.
+func (s *Settings) Config() *Config {
+	merged := &Config{Events: map[string][]string{}}
+	for _, layer := range s.layers() {
+		merged.Rules = append(merged.Rules, layer.Config.Rules...)
+	}
+	for _, key := range Keys() {
+		for _, layer := range s.layers() {
+			if values := key.Get(layer.Config); len(values) > 0 {
+				key.Set(merged, values)
+				break
+			}
+		}
+	}
+	return merged
+}
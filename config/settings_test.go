@@ -0,0 +1,324 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/rules"
+	"github.com/urfave/cli/v2"
+)
+
+// setupRepo makes a temporary repository with a repo config at its top, and a user config next to it.  The
+// current directory is a subdirectory of the repository.
+func setupRepo(t *testing.T, userConfig string, repoConfig string) (string, func()) {
+	base, err := os.MkdirTemp("", "temp-settings-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	repo := filepath.Join(base, "repo")
+	os.MkdirAll(filepath.Join(repo, "sub", "dir"), 0755)
+	os.WriteFile(filepath.Join(base, "user.yaml"), []byte(userConfig), 0644)
+	if repoConfig != "" {
+		os.WriteFile(filepath.Join(repo, RepoConfigName), []byte(repoConfig), 0644)
+	}
+	origTopLevel := GetTopLevel
+	origGetwd := Getwd
+	origGitConfig := GetGitConfig
+	GetGitConfig = func() (*Config, error) {
+		return &Config{}, nil
+	}
+	GetTopLevel = func() (string, error) {
+		return repo, nil
+	}
+	Getwd = func() (string, error) {
+		return filepath.Join(repo, "sub", "dir"), nil
+	}
+	return base, func() {
+		GetTopLevel = origTopLevel
+		Getwd = origGetwd
+		GetGitConfig = origGitConfig
+		os.RemoveAll(base)
+	}
+}
+
+// lookupWith runs an app with the user config in base, returning the value of the setting.
+func lookupWith(t *testing.T, base string, name string, args ...string) Value {
+	var value Value
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.PathFlag{Name: "library-base", Value: "/default/libraries"},
+			&cli.PathFlag{Name: "config", Value: filepath.Join(base, "user.yaml")},
+		},
+		Commands: []*cli.Command{
+			{
+				Name: "play",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "libraries", Value: cli.NewStringSlice("default")},
+				},
+				Action: func(c *cli.Context) error {
+					settings, err := LoadSettings(c)
+					if err != nil {
+						t.Fatalf("Error loading settings: %s", err.Error())
+					}
+					value = settings.Lookup(c, name)
+					return nil
+				},
+			},
+		},
+	}
+	if err := app.Run(append([]string{"push-sounds"}, args...)); err != nil {
+		t.Fatalf("Error running app: %s", err.Error())
+	}
+	return value
+}
+
+func TestLookupPrecedence(t *testing.T) {
+	base, cleanup := setupRepo(t, "libraries: [user]\nlibrary-base: /user/libraries\n", "libraries: [repo]\n")
+	defer cleanup()
+
+	value := lookupWith(t, base, "libraries", "play", "--libraries", "flag")
+	if value.Source != FlagSource || !reflect.DeepEqual(value.Values, []string{"flag"}) {
+		t.Errorf("A flag should win over everything, was: %#v", value)
+	}
+
+	os.Setenv("PUSH_SOUNDS_LIBRARIES", "env1, env2")
+	value = lookupWith(t, base, "libraries", "play")
+	os.Unsetenv("PUSH_SOUNDS_LIBRARIES")
+	if value.Source != EnvSource || !reflect.DeepEqual(value.Values, []string{"env1", "env2"}) {
+		t.Errorf("The environment should win over config files, was: %#v", value)
+	}
+
+	GetGitConfig = func() (*Config, error) {
+		return &Config{Libraries: []string{"git"}}, nil
+	}
+	value = lookupWith(t, base, "libraries", "play")
+	if value.Source != GitSource || !reflect.DeepEqual(value.Values, []string{"git"}) || value.Origin != "push-sounds.libraries" {
+		t.Errorf("Git config should win over config files, was: %#v", value)
+	}
+	GetGitConfig = func() (*Config, error) {
+		return &Config{}, nil
+	}
+
+	value = lookupWith(t, base, "libraries", "play")
+	if value.Source != RepoSource || !reflect.DeepEqual(value.Values, []string{"repo"}) {
+		t.Errorf("The repo config should win over the user config, was: %#v", value)
+	}
+
+	value = lookupWith(t, base, "library-base", "play")
+	if value.Source != UserSource || value.String() != "/user/libraries" {
+		t.Errorf("The user config should win over the default, was: %#v", value)
+	}
+}
+
+func TestLookupProfile(t *testing.T) {
+	user := "profile: work\nvolume: \"80\"\nprofiles:\n  work:\n    volume: \"20\"\n    libraries: [quiet]\n  home:\n    libraries: [memes]\n"
+	base, cleanup := setupRepo(t, user, "libraries: [repo]\n")
+	defer cleanup()
+
+	value := lookupWith(t, base, "volume", "play")
+	if value.Source != ProfileSource || value.String() != "20" {
+		t.Errorf("The current profile should win over the user config, was: %#v", value)
+	}
+	value = lookupWith(t, base, "libraries", "play")
+	if value.Source != RepoSource {
+		t.Errorf("The repo config should win over the profile, was: %#v", value)
+	}
+	os.Setenv("PUSH_SOUNDS_PROFILE", "home")
+	value = lookupWith(t, base, "volume", "play")
+	os.Unsetenv("PUSH_SOUNDS_PROFILE")
+	if value.Source != UserSource || value.String() != "80" {
+		t.Errorf("A profile chosen in the environment should be used instead, was: %#v", value)
+	}
+	os.Setenv("PUSH_SOUNDS_PROFILE", "work")
+	value = lookupWith(t, base, "libraries", "play")
+	os.Unsetenv("PUSH_SOUNDS_PROFILE")
+	if value.Source != ProfileSource || value.String() != "quiet" {
+		t.Errorf("A profile chosen in the environment should win over the repo config, was: %#v", value)
+	}
+}
+
+func TestLookupDefault(t *testing.T) {
+	base, cleanup := setupRepo(t, "", "")
+	defer cleanup()
+
+	value := lookupWith(t, base, "library-base", "play")
+	if value.Source != DefaultSource || value.String() != "/default/libraries" {
+		t.Errorf("The flag's default should be used without any config, was: %#v", value)
+	}
+	value = lookupWith(t, base, "failure-libraries", "play")
+	if value.Source != DefaultSource || !reflect.DeepEqual(value.Values, []string{"failure"}) {
+		t.Errorf("The key's default should be used without a flag, was: %#v", value)
+	}
+}
+
+func TestRepoConfigPath(t *testing.T) {
+	base, cleanup := setupRepo(t, "", "libraries: [repo]\n")
+	defer cleanup()
+
+	expected := filepath.Join(base, "repo", RepoConfigName)
+	if path := RepoConfigPath(); path != expected {
+		t.Errorf("Repo config should be found at the top of the repository '%s', was '%s'", expected, path)
+	}
+	nearer := filepath.Join(base, "repo", "sub", RepoConfigName)
+	os.WriteFile(nearer, []byte("libraries: [sub]\n"), 0644)
+	if path := RepoConfigPath(); path != nearer {
+		t.Errorf("The nearest repo config '%s' should be used, was '%s'", nearer, path)
+	}
+
+	// a config above the repository doesn't belong to it
+	os.Remove(nearer)
+	os.Remove(expected)
+	os.WriteFile(filepath.Join(base, RepoConfigName), []byte("libraries: [outside]\n"), 0644)
+	if path := RepoConfigPath(); path != "" {
+		t.Errorf("A config outside the repository should not be used, was '%s'", path)
+	}
+}
+
+func TestSettingsConfigMerge(t *testing.T) {
+	settings := &Settings{
+		Git: Layer{Source: GitSource, Config: &Config{
+			Events: map[string][]string{"force": {"git-force"}},
+		}},
+		User: Layer{Source: UserSource, Config: &Config{
+			Events: map[string][]string{"tag": {"user-tag"}, "force": {"user-force"}},
+			Rules:  []rules.Rule{{Name: "user", Mute: true}},
+		}},
+		Repo: Layer{Source: RepoSource, Config: &Config{
+			Events: map[string][]string{"tag": {"repo-tag"}},
+			Rules:  []rules.Rule{{Name: "repo", Mute: true}},
+		}},
+		Profile: Layer{Source: ProfileSource, Config: &Config{
+			Events: map[string][]string{"delete": {"profile-delete"}},
+			Rules:  []rules.Rule{{Name: "profile", Mute: true}},
+		}},
+	}
+	merged := settings.Config()
+	expected := map[string][]string{"tag": {"repo-tag"}, "force": {"git-force"}, "delete": {"profile-delete"}}
+	if !reflect.DeepEqual(merged.Events, expected) {
+		t.Errorf("Events should be merged with git config then the repo config winning, was: %#v", merged.Events)
+	}
+	if len(merged.Rules) != 3 || merged.Rules[0].Name != "repo" || merged.Rules[1].Name != "profile" || merged.Rules[2].Name != "user" {
+		t.Errorf("Repo rules should be checked before profile rules, then user rules, was: %#v", merged.Rules)
+	}
+	settings.ProfileChosen = true
+	merged = settings.Config()
+	if len(merged.Rules) != 3 || merged.Rules[0].Name != "profile" || merged.Rules[1].Name != "repo" {
+		t.Errorf("The rules of a chosen profile should be checked before repo rules, was: %#v", merged.Rules)
+	}
+}
+
+func TestSetSetting(t *testing.T) {
+	base, cleanup := setupRepo(t, "", "")
+	defer cleanup()
+	app := &cli.App{
+		Flags:    []cli.Flag{&cli.PathFlag{Name: "config", Value: filepath.Join(base, "user.yaml")}},
+		Commands: []*cli.Command{ConfigCommand},
+		Writer:   io.Discard,
+	}
+
+	if err := app.Run([]string{"push-sounds", "config", "set", "events.tag", "fanfare", "trumpets"}); err != nil {
+		t.Fatalf("Error setting user config: %s", err.Error())
+	}
+	if err := app.Run([]string{"push-sounds", "config", "set", "--repo", "libraries", "repo"}); err != nil {
+		t.Fatalf("Error setting repo config: %s", err.Error())
+	}
+	user, _ := Load(filepath.Join(base, "user.yaml"))
+	if !reflect.DeepEqual(user.Events["tag"], []string{"fanfare", "trumpets"}) {
+		t.Errorf("User config should have tag libraries set, was: %#v", user)
+	}
+	repo, _ := Load(filepath.Join(base, "repo", RepoConfigName))
+	if !reflect.DeepEqual(repo.Libraries, []string{"repo"}) {
+		t.Errorf("Repo config should have been created at the top of the repository, was: %#v", repo)
+	}
+	if err := app.Run([]string{"push-sounds", "config", "set", "library-base", "a", "b"}); err == nil {
+		t.Errorf("Setting several values for library-base should return an error")
+	}
+}
+
+func TestGitConfig(t *testing.T) {
+	base, cleanup := setupRepo(t, "", "")
+	defer cleanup()
+	origGitConfig, hadGitConfig := os.LookupEnv("GIT_CONFIG_GLOBAL")
+	defer func() {
+		if hadGitConfig {
+			os.Setenv("GIT_CONFIG_GLOBAL", origGitConfig)
+		} else {
+			os.Unsetenv("GIT_CONFIG_GLOBAL")
+		}
+	}()
+	os.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(base, "gitconfig"))
+	content := "[push-sounds]\n\tenabled\n\tvolume = 50\n\tlibraries = memes, classics\n\tlibraries = extra\n\tunknown = ignored\n" +
+		"[push-sounds \"events\"]\n\ttag = fanfare\n"
+	os.WriteFile(filepath.Join(base, "gitconfig"), []byte(content), 0644)
+
+	config, err := gitConfig()
+	if err != nil {
+		t.Fatalf("Error reading git config: %s", err.Error())
+	}
+	expected := &Config{
+		Enabled:   "true",
+		Volume:    "50",
+		Libraries: []string{"memes", "classics", "extra"},
+		Events:    map[string][]string{"tag": {"fanfare"}},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected git config %#v, was %#v", expected, config)
+	}
+}
+
+func TestValueBool(t *testing.T) {
+	key, _ := LookupKey("enabled")
+	for value, expected := range map[string]bool{"true": true, "yes": true, "On": true, "false": false, "no": false, "0": false} {
+		actual, err := Value{Key: key, Values: []string{value}}.Bool()
+		if err != nil || actual != expected {
+			t.Errorf("Expected '%s' to be %t, was %t (err: %v)", value, expected, actual, err)
+		}
+	}
+	if _, err := (Value{Key: key, Values: []string{"maybe"}}).Bool(); err == nil {
+		t.Errorf("Expected 'maybe' to not be a boolean")
+	}
+	if err := key.Set(&Config{}, []string{"maybe"}); err == nil {
+		t.Errorf("Setting enabled to 'maybe' should return an error")
+	}
+}
+
+func TestValueDuration(t *testing.T) {
+	key, _ := LookupKey("cooldown")
+	for value, expected := range map[string]time.Duration{"0s": 0, "10s": 10 * time.Second, "1m30s": 90 * time.Second} {
+		actual, err := Value{Key: key, Values: []string{value}}.Duration()
+		if err != nil || actual != expected {
+			t.Errorf("Expected '%s' to be %s, was %s (err: %v)", value, expected, actual, err)
+		}
+	}
+	for _, value := range []string{"10", "soon", "-5s"} {
+		if err := key.Set(&Config{}, []string{value}); err == nil {
+			t.Errorf("Setting cooldown to '%s' should return an error", value)
+		}
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	base, cleanup := setupRepo(t, "profiles:\n  work:\n    volume: \"20\"\n", "")
+	defer cleanup()
+	app := &cli.App{
+		Flags:     []cli.Flag{&cli.PathFlag{Name: "config", Value: filepath.Join(base, "user.yaml")}, &cli.StringFlag{Name: "profile"}},
+		Commands:  []*cli.Command{ProfileCommand},
+		Writer:    io.Discard,
+		ErrWriter: io.Discard,
+	}
+
+	if err := app.Run([]string{"push-sounds", "profile", "use", "home"}); err == nil {
+		t.Errorf("Using a profile that doesn't exist should return an error")
+	}
+	if err := app.Run([]string{"push-sounds", "profile", "use", "work"}); err != nil {
+		t.Fatalf("Error using profile: %s", err.Error())
+	}
+	user, _ := Load(filepath.Join(base, "user.yaml"))
+	if user.Profile != "work" || user.Profiles["work"].Volume != "20" {
+		t.Errorf("The user config should be using the work profile and still have it, was: %#v", user)
+	}
+}
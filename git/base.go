@@ -0,0 +1,94 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	Run = run
+)
+
+// Error is returned by Run when git exits unsuccessfully.
+type Error struct {
+	Args     []string
+	ExitCode int
+	Stderr   string
+}
+
+func (e *Error) Error() string {
+	message := e.Stderr
+	if message == "" {
+		message = fmt.Sprintf("exit status %d", e.ExitCode)
+	}
+	return fmt.Sprintf("git %s failed: %s", strings.Join(e.Args, " "), message)
+}
+
+func run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", &Error{Args: args, ExitCode: exitErr.ExitCode(), Stderr: strings.TrimSpace(stderr.String())}
+		}
+		return "", fmt.Errorf("unable to run git: %s", err.Error())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// ConfigValue returns the value of a git config key, or an empty string if it isn't set.
+// Any arguments before the key (like --global or --path) are passed to git config.
+func ConfigValue(args ...string) (string, error) {
+	value, err := Run(append([]string{"config", "--get"}, args...)...)
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		// git config exits with 1 when the key isn't set
+		return "", nil
+	}
+	return value, err
+}
+
+func TopLevel() (string, error) {
+	return Run("rev-parse", "--show-toplevel")
+}
+
+// CommonDir returns the absolute path of the git directory shared by all worktrees of the current repository.
+func CommonDir() (string, error) {
+	commonDir, err := Run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(commonDir)
+}
+
+// HooksDir returns the directory git will run hooks from, honoring core.hooksPath.
+func HooksDir() (string, error) {
+	hooksPath, err := ConfigValue("--path", "core.hooksPath")
+	if err != nil {
+		return "", err
+	}
+	if hooksPath == "" {
+		commonDir, err := CommonDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(commonDir, "hooks"), nil
+	}
+	if filepath.IsAbs(hooksPath) {
+		return hooksPath, nil
+	}
+	// relative hook paths are resolved from the top of the working tree
+	topLevel, err := TopLevel()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(topLevel, hooksPath), nil
+}
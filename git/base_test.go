@@ -0,0 +1,118 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func createTempRepo(t *testing.T) string {
+	repo, err := os.MkdirTemp("", "temp-repo-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary repository: %s", err.Error())
+	}
+	repo, _ = filepath.EvalSymlinks(repo)
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		os.RemoveAll(repo)
+		t.Fatalf("unable to initialize temporary repository: %s", out)
+	}
+	return repo
+}
+
+func chdir(t *testing.T, dir string) func() {
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unable to get working directory: %s", err.Error())
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("unable to change directory to %s: %s", dir, err.Error())
+	}
+	return func() {
+		os.Chdir(orig)
+	}
+}
+
+func TestRunError(t *testing.T) {
+	_, err := Run("not-a-real-git-command")
+	if err == nil {
+		t.Fatal("Running an invalid git command should have returned an error")
+	}
+	if _, ok := err.(*Error); !ok {
+		t.Errorf("Error from a failing git command should have been a *git.Error, was: %#v", err)
+	}
+}
+
+func TestConfigValueNotSet(t *testing.T) {
+	repo := createTempRepo(t)
+	defer os.RemoveAll(repo)
+	defer chdir(t, repo)()
+
+	value, err := ConfigValue("push-sounds.does-not-exist")
+	if err != nil {
+		t.Fatalf("A missing config key should not be an error: %s", err.Error())
+	}
+	if value != "" {
+		t.Errorf("A missing config key should have an empty value, was: %s", value)
+	}
+}
+
+func TestHooksDirDefault(t *testing.T) {
+	repo := createTempRepo(t)
+	defer os.RemoveAll(repo)
+	defer chdir(t, repo)()
+
+	hooksDir, err := HooksDir()
+	if err != nil {
+		t.Fatalf("Unable to get hooks dir: %s", err.Error())
+	}
+	expected := filepath.Join(repo, ".git", "hooks")
+	if hooksDir != expected {
+		t.Errorf("Expected hooks dir to be '%s', but was '%s'", expected, hooksDir)
+	}
+}
+
+func TestHooksDirRelativeHooksPath(t *testing.T) {
+	repo := createTempRepo(t)
+	defer os.RemoveAll(repo)
+	defer chdir(t, repo)()
+
+	if _, err := Run("config", "core.hooksPath", "custom-hooks"); err != nil {
+		t.Fatalf("Unable to set core.hooksPath: %s", err.Error())
+	}
+	os.Mkdir(filepath.Join(repo, "sub"), 0755)
+	defer chdir(t, filepath.Join(repo, "sub"))()
+
+	hooksDir, err := HooksDir()
+	if err != nil {
+		t.Fatalf("Unable to get hooks dir: %s", err.Error())
+	}
+	expected := filepath.Join(repo, "custom-hooks")
+	if hooksDir != expected {
+		t.Errorf("Expected hooks dir to be '%s', but was '%s'", expected, hooksDir)
+	}
+}
+
+func TestHooksDirWorktree(t *testing.T) {
+	repo := createTempRepo(t)
+	defer os.RemoveAll(repo)
+	defer chdir(t, repo)()
+
+	if _, err := Run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"); err != nil {
+		t.Fatalf("Unable to create initial commit: %s", err.Error())
+	}
+	worktree := filepath.Join(repo, "worktree")
+	if _, err := Run("worktree", "add", "-q", worktree); err != nil {
+		t.Fatalf("Unable to create worktree: %s", err.Error())
+	}
+	defer chdir(t, worktree)()
+
+	hooksDir, err := HooksDir()
+	if err != nil {
+		t.Fatalf("Unable to get hooks dir: %s", err.Error())
+	}
+	expected := filepath.Join(repo, ".git", "hooks")
+	if hooksDir != expected {
+		t.Errorf("Expected worktree hooks dir to be the common '%s', but was '%s'", expected, hooksDir)
+	}
+}
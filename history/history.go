@@ -0,0 +1,141 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+const (
+	fileName = "history.jsonl"
+
+	// once the history is bigger than compactSize it is compacted down to the latest keepEntries plays
+	compactSize = 1 << 20
+	keepEntries = 5000
+)
+
+var (
+	Now = time.Now
+)
+
+// Entry is one sound that was played.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	File    string    `json:"file"`
+	Library string    `json:"library"`
+	Repo    string    `json:"repo,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	Event   string    `json:"event,omitempty"`
+	// Reason is the rule or setting that chose the libraries the sound came from.
+	Reason   string        `json:"reason,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Path is where the history is kept.
+func Path() (string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, fileName), nil
+}
+
+// Record adds a play to the end of the history, setting its time if it doesn't have one.
+func Record(entry Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if entry.Time.IsZero() {
+		entry.Time = Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to record %s in the history: %s", entry.File, err.Error())
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to create the history directory: %s", err.Error())
+	}
+	unlock, err := state.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open the history %s: %s", path, err.Error())
+	}
+	_, err = file.Write(append(line, '\n'))
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("unable to record %s in the history: %s", entry.File, err.Error())
+	}
+	if stat, err := os.Stat(path); err == nil && stat.Size() > compactSize {
+		return compact(path, keepEntries)
+	}
+	return nil
+}
+
+// Load reads every play in the history, oldest first.  A missing history has no plays.
+func Load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return load(path)
+}
+
+// Last is the latest play in the history, found is false when nothing has played yet.
+func Last() (entry Entry, found bool, err error) {
+	entries, err := Load()
+	if err != nil || len(entries) == 0 {
+		return Entry{}, false, err
+	}
+	return entries[len(entries)-1], true, nil
+}
+
+func load(path string) ([]Entry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the history %s: %s", path, err.Error())
+	}
+	entries := []Entry{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		entry := Entry{}
+		// a line cut short by a crash is skipped rather than losing the rest of the history
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// compact rewrites the history with only the latest keep plays, the caller must hold the lock.
+func compact(path string, keep int) error {
+	entries, err := load(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) > keep {
+		entries = entries[len(entries)-keep:]
+	}
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	for _, entry := range entries {
+		encoder.Encode(entry)
+	}
+	if err := state.WriteFile(path, content.Bytes()); err != nil {
+		return fmt.Errorf("unable to compact the history: %s", err.Error())
+	}
+	return nil
+}
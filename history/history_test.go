@@ -0,0 +1,85 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	statetest.UseTemp(t)
+	when := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	origNow := Now
+	defer func() {
+		Now = origNow
+	}()
+	Now = func() time.Time {
+		return when
+	}
+	if entries, err := Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("A missing history should have no plays, was %v (err: %v)", entries, err)
+	}
+	Record(Entry{File: "a", Library: "default", Repo: "/repo", Branch: "main", Event: "update"})
+	Record(Entry{File: "b", Library: "memes", Time: when.Add(time.Hour)})
+	entries, err := Load()
+	if err != nil {
+		t.Fatalf("Unable to load history: %s", err.Error())
+	}
+	if len(entries) != 2 || entries[0].File != "a" || entries[0].Branch != "main" || entries[1].Library != "memes" {
+		t.Fatalf("The history should have both plays in order, was %v", entries)
+	}
+	if !entries[0].Time.Equal(when) || !entries[1].Time.Equal(when.Add(time.Hour)) {
+		t.Errorf("Plays without a time should be recorded now, was %v", entries)
+	}
+}
+
+func TestLoadSkipsBrokenLines(t *testing.T) {
+	stateDir := statetest.UseTemp(t)
+	Record(Entry{File: "a"})
+	path := filepath.Join(stateDir, "push-sounds", fileName)
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"file": "cut sh`)
+	file.Close()
+	entries, err := Load()
+	if err != nil || len(entries) != 1 {
+		t.Errorf("A broken line should be skipped, was %v (err: %v)", entries, err)
+	}
+}
+
+func TestCompactKeepsLatest(t *testing.T) {
+	stateDir := statetest.UseTemp(t)
+	for _, file := range []string{"a", "b", "c", "d"} {
+		Record(Entry{File: file})
+	}
+	if err := compact(filepath.Join(stateDir, "push-sounds", fileName), 2); err != nil {
+		t.Fatalf("Unable to compact history: %s", err.Error())
+	}
+	entries, _ := Load()
+	if len(entries) != 2 || entries[0].File != "c" || entries[1].File != "d" {
+		t.Errorf("Compacting should keep the latest plays, was %v", entries)
+	}
+}
+
+func TestRecordConcurrently(t *testing.T) {
+	statetest.UseTemp(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := Record(Entry{File: strings.Repeat("x", i+1)}); err != nil {
+				t.Errorf("Unable to record play: %s", err.Error())
+			}
+		}(i)
+	}
+	wg.Wait()
+	entries, _ := Load()
+	if len(entries) != 20 {
+		t.Errorf("Every play should be recorded, recorded %d", len(entries))
+	}
+}
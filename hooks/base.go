@@ -0,0 +1,257 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	PrePush              = "pre-push"
+	ReferenceTransaction = "reference-transaction"
+
+	originalSuffix = ".push-sounds-orig"
+
+	blockStart = "# >>> push-sounds >>>"
+	blockEnd   = "# <<< push-sounds <<<"
+	shebang    = "#!/bin/sh"
+)
+
+var (
+	Executable = os.Executable
+)
+
+type HookState int
+
+const (
+	NotInstalled HookState = iota
+	Installed
+	Foreign
+)
+
+func (s HookState) Name() string {
+	switch s {
+	case NotInstalled:
+		return "not installed"
+	case Installed:
+		return "installed"
+	case Foreign:
+		return "not managed by push-sounds"
+	default:
+		return "unknown"
+	}
+}
+
+// Hook is a single git hook script in a hooks directory.
+type Hook struct {
+	Dir  string
+	Name string
+}
+
+func (h Hook) Path() string {
+	return filepath.Join(h.Dir, h.Name)
+}
+
+// OriginalPath is where a hook that existed before push-sounds was installed is kept, so it can still be run
+// and restored on uninstall.
+func (h Hook) OriginalPath() string {
+	return h.Path() + originalSuffix
+}
+
+// Exists checks the hook file is there.
+func (h Hook) Exists() bool {
+	_, err := os.Stat(h.Path())
+	return err == nil
+}
+
+// Original returns the path of the preserved original hook, or an empty string if there isn't one.
+func (h Hook) Original() string {
+	if _, err := os.Stat(h.OriginalPath()); err != nil {
+		return ""
+	}
+	return h.OriginalPath()
+}
+
+// PreserveOriginal moves a hook that isn't managed by push-sounds out of the way so the push-sounds hook can
+// chain to it.  It returns the path of the original hook to chain to, or an empty string if there isn't one, and
+// whether the hook was moved, so it can be put back with RestoreOriginal if installing fails.  When force is true
+// a foreign hook is left to be replaced rather than preserved.
+func (h Hook) PreserveOriginal(force bool) (string, bool, error) {
+	state, _, err := h.State()
+	if err != nil {
+		return "", false, err
+	}
+	if state != Foreign || force {
+		return h.Original(), false, nil
+	}
+	if h.Original() != "" {
+		return "", false, fmt.Errorf("%s is not managed by push-sounds, but an original hook is already preserved at %s, use --force to replace it", h.Path(), h.OriginalPath())
+	}
+	if err := os.Rename(h.Path(), h.OriginalPath()); err != nil {
+		return "", false, fmt.Errorf("unable to preserve existing hook %s: %s", h.Path(), err.Error())
+	}
+	return h.OriginalPath(), true, nil
+}
+
+// RestoreOriginal moves the preserved original hook back into place.
+func (h Hook) RestoreOriginal() error {
+	if err := os.Rename(h.OriginalPath(), h.Path()); err != nil {
+		return fmt.Errorf("unable to restore original hook %s: %s", h.OriginalPath(), err.Error())
+	}
+	return nil
+}
+
+// State reports whether the hook exists and if it contains a push-sounds managed block, along with the
+// managed block's contents.
+func (h Hook) State() (HookState, string, error) {
+	content, err := os.ReadFile(h.Path())
+	if err != nil && os.IsNotExist(err) {
+		return NotInstalled, "", nil
+	}
+	if err != nil {
+		return NotInstalled, "", fmt.Errorf("unable to read hook %s: %s", h.Path(), err.Error())
+	}
+	block, found := managedBlock(string(content))
+	if !found {
+		return Foreign, "", nil
+	}
+	return Installed, block, nil
+}
+
+// Install writes the managed block into the hook, replacing only the text of any previous managed block.  An existing hook
+// that wasn't written by push-sounds is only replaced when force is true.
+func (h Hook) Install(command string, force bool) error {
+	state, _, err := h.State()
+	if err != nil {
+		return err
+	}
+	if state == Foreign && !force {
+		return fmt.Errorf("%s already exists and is not managed by push-sounds, use --force to replace it", h.Path())
+	}
+	if err := os.MkdirAll(h.Dir, 0755); err != nil {
+		return fmt.Errorf("unable to create hooks directory %s: %s", h.Dir, err.Error())
+	}
+	block := strings.Join([]string{blockStart, command, blockEnd}, "\n")
+	script := strings.Join([]string{shebang, block, ""}, "\n")
+	if state == Installed {
+		// only the managed block is push-sounds', anything the user added around it is kept
+		content, err := os.ReadFile(h.Path())
+		if err != nil {
+			return fmt.Errorf("unable to read hook %s: %s", h.Path(), err.Error())
+		}
+		start, end, _ := blockBounds(string(content))
+		script = string(content[:start]) + block + string(content[end:])
+	}
+	if err := os.WriteFile(h.Path(), []byte(script), 0755); err != nil {
+		return fmt.Errorf("unable to write hook %s: %s", h.Path(), err.Error())
+	}
+	// WriteFile doesn't change the mode of an existing file
+	if err := os.Chmod(h.Path(), 0755); err != nil {
+		return fmt.Errorf("unable to make hook %s executable: %s", h.Path(), err.Error())
+	}
+	return nil
+}
+
+// Uninstall removes the managed block from the hook, and the hook itself when nothing but the shebang is left,
+// restoring the original hook if one was preserved.  A preserved original is left where it is when the hook has
+// other lines.  It returns false if there was nothing to remove.
+func (h Hook) Uninstall() (bool, error) {
+	state, _, err := h.State()
+	if err != nil || state != Installed {
+		return false, err
+	}
+	content, err := os.ReadFile(h.Path())
+	if err != nil {
+		return false, fmt.Errorf("unable to read hook %s: %s", h.Path(), err.Error())
+	}
+	start, end, _ := blockBounds(string(content))
+	rest := string(content[:start]) + strings.TrimPrefix(string(content[end:]), "\n")
+	if remaining := strings.TrimSpace(rest); remaining != "" && remaining != shebang {
+		if err := os.WriteFile(h.Path(), []byte(rest), 0755); err != nil {
+			return false, fmt.Errorf("unable to write hook %s: %s", h.Path(), err.Error())
+		}
+		return true, nil
+	}
+	if err := os.Remove(h.Path()); err != nil {
+		return false, fmt.Errorf("unable to remove hook %s: %s", h.Path(), err.Error())
+	}
+	if h.Original() != "" {
+		if err := h.RestoreOriginal(); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// blockBounds finds where the managed block starts and ends in the content of a hook, including its markers.
+func blockBounds(content string) (int, int, bool) {
+	start := strings.Index(content, blockStart)
+	if start < 0 {
+		return 0, 0, false
+	}
+	end := strings.Index(content[start:], blockEnd)
+	if end < 0 {
+		return 0, 0, false
+	}
+	return start, start + end + len(blockEnd), true
+}
+
+func managedBlock(content string) (string, bool) {
+	start, end, found := blockBounds(content)
+	if !found {
+		return "", false
+	}
+	return strings.TrimSpace(content[start+len(blockStart) : end-len(blockEnd)]), true
+}
+
+// PlayCommand builds the shell command a hook uses to run push-sounds play with playArgs, passing globalArgs
+// before the play command.  Arguments starting with -- are used as-is, everything else is quoted.  When original
+// is set, push-sounds runs that hook first and exits with its status, and the hook exits with it when it failed so
+// lines the user added after the managed block only run after a passing hook.  The original is a shell word, so it
+// must already be quoted.
+func PlayCommand(globalArgs []string, playArgs []string, original string) (string, error) {
+	executable, err := Executable()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the push-sounds executable: %s", err.Error())
+	}
+	args := append([]string{shellQuote(executable)}, shellArgs(globalArgs)...)
+	args = append(args, "play")
+	args = append(args, shellArgs(playArgs)...)
+	if original == "" {
+		// a sound should never be the reason a push fails
+		return strings.Join(append(args, "--", `"$@"`), " ") + " || true", nil
+	}
+	args = append(args, "--chain", original, "--", `"$@"`)
+	return strings.Join(args, " ") + " || exit $?", nil
+}
+
+func shellArgs(args []string) []string {
+	quoted := []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			quoted = append(quoted, arg)
+		} else {
+			quoted = append(quoted, shellQuote(arg))
+		}
+	}
+	return quoted
+}
+
+// GlobalArgs returns the global push-sounds flags that were set, so hooks run with the same settings they were
+// installed with.
+func GlobalArgs(c *cli.Context) []string {
+	args := []string{}
+	for _, name := range []string{"library-base", "config"} {
+		if c.IsSet(name) {
+			args = append(args, "--"+name, c.String(name))
+		}
+	}
+	return args
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,315 @@
+package hooks
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func createTempHook(t *testing.T) Hook {
+	dir, err := os.MkdirTemp("", "temp-hooks-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary hooks directory: %s", err.Error())
+	}
+	return Hook{Dir: dir, Name: PrePush}
+}
+
+func TestHookInstallNew(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+
+	err := hook.Install("push-sounds play", false)
+	if err != nil {
+		t.Fatalf("Error installing hook into empty directory: %s", err.Error())
+	}
+	state, block, err := hook.State()
+	if err != nil {
+		t.Fatalf("Error getting hook state: %s", err.Error())
+	}
+	if state != Installed {
+		t.Errorf("Hook should have been installed, was: %s", state.Name())
+	}
+	if block != "push-sounds play" {
+		t.Errorf("Managed block should have been 'push-sounds play', was: %#v", block)
+	}
+	stat, err := os.Stat(hook.Path())
+	if err != nil {
+		t.Fatalf("Unable to stat hook: %s", err.Error())
+	}
+	if stat.Mode().Perm()&0111 == 0 {
+		t.Errorf("Hook should be executable, mode was: %s", stat.Mode())
+	}
+}
+
+func TestHookInstallIdempotent(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+
+	for _, command := range []string{"push-sounds play", "push-sounds play --libraries memes"} {
+		if err := hook.Install(command, false); err != nil {
+			t.Fatalf("Error installing hook: %s", err.Error())
+		}
+	}
+	_, block, _ := hook.State()
+	if block != "push-sounds play --libraries memes" {
+		t.Errorf("Reinstalling should replace the managed block, was: %#v", block)
+	}
+	content, _ := os.ReadFile(hook.Path())
+	if strings.Count(string(content), blockStart) != 1 {
+		t.Errorf("Reinstalling should not duplicate the managed block:\n%s", content)
+	}
+}
+
+func TestHookInstallRefusesForeign(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	foreign := "#!/bin/sh\nexec lint\n"
+	os.WriteFile(hook.Path(), []byte(foreign), 0755)
+
+	err := hook.Install("push-sounds play", false)
+	if err == nil {
+		t.Fatal("Installing over a foreign hook without force should have returned an error")
+	}
+	content, _ := os.ReadFile(hook.Path())
+	if string(content) != foreign {
+		t.Errorf("Foreign hook should not have been modified, was:\n%s", content)
+	}
+
+	err = hook.Install("push-sounds play", true)
+	if err != nil {
+		t.Fatalf("Installing over a foreign hook with force should succeed: %s", err.Error())
+	}
+	state, _, _ := hook.State()
+	if state != Installed {
+		t.Errorf("Hook should have been installed after force, was: %s", state.Name())
+	}
+}
+
+func TestHookUninstall(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+
+	removed, err := hook.Uninstall()
+	if err != nil || removed {
+		t.Errorf("Uninstalling a missing hook should do nothing, removed: %t, err: %v", removed, err)
+	}
+	hook.Install("push-sounds play", false)
+	removed, err = hook.Uninstall()
+	if err != nil || !removed {
+		t.Errorf("Uninstalling an installed hook should remove it, removed: %t, err: %v", removed, err)
+	}
+	if _, err := os.Stat(hook.Path()); !os.IsNotExist(err) {
+		t.Errorf("Hook file should have been removed")
+	}
+}
+
+func TestHookKeepsUserLines(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	content := "#!/bin/sh\necho lint\n" + blockStart + "\npush-sounds play\n" + blockEnd + "\necho after\n"
+	os.WriteFile(hook.Path(), []byte(content), 0755)
+
+	if err := hook.Install("push-sounds play --libraries memes", false); err != nil {
+		t.Fatalf("Error reinstalling hook: %s", err.Error())
+	}
+	installed, _ := os.ReadFile(hook.Path())
+	expected := "#!/bin/sh\necho lint\n" + blockStart + "\npush-sounds play --libraries memes\n" + blockEnd + "\necho after\n"
+	if string(installed) != expected {
+		t.Errorf("Reinstalling should only replace the managed block, was:\n%s", installed)
+	}
+
+	removed, err := hook.Uninstall()
+	if err != nil || !removed {
+		t.Errorf("Uninstalling should remove the managed block, removed: %t, err: %v", removed, err)
+	}
+	uninstalled, err := os.ReadFile(hook.Path())
+	if err != nil {
+		t.Fatalf("A hook with other lines should not have been removed: %s", err.Error())
+	}
+	if string(uninstalled) != "#!/bin/sh\necho lint\necho after\n" {
+		t.Errorf("Uninstalling should only remove the managed block, was:\n%s", uninstalled)
+	}
+}
+
+func TestChainedHookRunsUserLines(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	orig := Executable
+	defer func() { Executable = orig }()
+	// push-sounds exits with the chained hook's status, given here as its first argument
+	fake := filepath.Join(hook.Dir, "push-sounds")
+	os.WriteFile(fake, []byte("#!/bin/sh\nfor arg; do last=$arg; done\nexit $last\n"), 0755)
+	Executable = func() (string, error) {
+		return fake, nil
+	}
+	command, err := PlayCommand([]string{}, []string{}, "'chained'")
+	if err != nil {
+		t.Fatalf("Error building play command: %s", err.Error())
+	}
+	if err := hook.Install(command, false); err != nil {
+		t.Fatalf("Error installing hook: %s", err.Error())
+	}
+	content, _ := os.ReadFile(hook.Path())
+	os.WriteFile(hook.Path(), append(content, []byte("echo after\n")...), 0755)
+
+	out, err := exec.Command(hook.Path(), "0").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "after" {
+		t.Errorf("Lines after the managed block should run after a passing hook, output: %#v, err: %v", string(out), err)
+	}
+	out, err = exec.Command(hook.Path(), "3").Output()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 3 || len(out) != 0 {
+		t.Errorf("The hook should exit with a failing status before the lines after the block, output: %#v, err: %v", string(out), err)
+	}
+}
+
+func TestUninstallWarnsAboutOriginal(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	orig := GetHooksDir
+	defer func() { GetHooksDir = orig }()
+	GetHooksDir = func() (string, error) {
+		return hook.Dir, nil
+	}
+	os.WriteFile(hook.OriginalPath(), []byte("#!/bin/sh\nexec lint\n"), 0755)
+	os.WriteFile(hook.Path(), []byte("#!/bin/sh\n"+blockStart+"\npush-sounds play\n"+blockEnd+"\necho after\n"), 0755)
+	after := Hook{Dir: hook.Dir, Name: ReferenceTransaction}
+	after.Install("push-sounds play --from-reference-transaction", false)
+
+	var errors strings.Builder
+	app := &cli.App{Commands: []*cli.Command{HooksCommand}, ErrWriter: &errors}
+	if err := app.Run([]string{"push-sounds", "hooks", "uninstall"}); err != nil {
+		t.Fatalf("Uninstalling a hook with other lines should not return an error: %s", err.Error())
+	}
+	if !strings.Contains(errors.String(), hook.OriginalPath()) {
+		t.Errorf("Uninstalling should warn that the original hook was left in place, warned: %s", errors.String())
+	}
+	if after.Exists() {
+		t.Errorf("The reference-transaction hook should still be uninstalled")
+	}
+}
+
+func TestHookUninstallLeavesForeign(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	os.WriteFile(hook.Path(), []byte("#!/bin/sh\nexec lint\n"), 0755)
+
+	removed, err := hook.Uninstall()
+	if err != nil || removed {
+		t.Errorf("Uninstalling a foreign hook should do nothing, removed: %t, err: %v", removed, err)
+	}
+	if _, err := os.Stat(hook.Path()); err != nil {
+		t.Errorf("Foreign hook should not have been removed: %s", err.Error())
+	}
+}
+
+func TestPlayCommand(t *testing.T) {
+	orig := Executable
+	defer func() { Executable = orig }()
+	Executable = func() (string, error) {
+		return "/usr/bin/push-sounds", nil
+	}
+
+	command, err := PlayCommand([]string{"--library-base", "/it's/here"}, []string{"--libraries", "default", "--libraries", "memes", "--from-pre-push"}, "")
+	if err != nil {
+		t.Fatalf("Error building play command: %s", err.Error())
+	}
+	expected := `'/usr/bin/push-sounds' --library-base '/it'\''s/here' play --libraries 'default' --libraries 'memes' --from-pre-push -- "$@" || true`
+	if command != expected {
+		t.Errorf("Expected play command to be:\n\t%s\nwas:\n\t%s", expected, command)
+	}
+}
+
+func TestHookPreserveOriginal(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	foreign := "#!/bin/sh\nexec lint\n"
+	os.WriteFile(hook.Path(), []byte(foreign), 0755)
+
+	original, moved, err := hook.PreserveOriginal(false)
+	if err != nil || !moved {
+		t.Fatalf("Error preserving original hook, moved: %t, err: %v", moved, err)
+	}
+	if original != hook.OriginalPath() {
+		t.Errorf("Expected original hook to be preserved at '%s', was '%s'", hook.OriginalPath(), original)
+	}
+	if err := hook.Install("push-sounds play", false); err != nil {
+		t.Fatalf("Error installing hook after preserving the original: %s", err.Error())
+	}
+	original, moved, err = hook.PreserveOriginal(false)
+	if err != nil || moved || original != hook.OriginalPath() {
+		t.Errorf("Reinstalling should keep chaining to the original hook, original: %s, err: %v", original, err)
+	}
+
+	removed, err := hook.Uninstall()
+	if err != nil || !removed {
+		t.Fatalf("Uninstalling should remove the push-sounds hook, removed: %t, err: %v", removed, err)
+	}
+	content, _ := os.ReadFile(hook.Path())
+	if string(content) != foreign {
+		t.Errorf("Uninstalling should restore the original hook, was:\n%s", content)
+	}
+	if hook.Original() != "" {
+		t.Errorf("Original hook should no longer be preserved after uninstall")
+	}
+}
+
+func TestHookPreserveOriginalRefusesSecondForeign(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	os.WriteFile(hook.OriginalPath(), []byte("#!/bin/sh\nexec lint\n"), 0755)
+	os.WriteFile(hook.Path(), []byte("#!/bin/sh\nexec scan\n"), 0755)
+
+	_, _, err := hook.PreserveOriginal(false)
+	if err == nil {
+		t.Fatal("Preserving a hook when an original is already preserved should return an error")
+	}
+	original, moved, err := hook.PreserveOriginal(true)
+	if err != nil || moved || original != hook.OriginalPath() {
+		t.Errorf("Forcing should replace the foreign hook and keep the preserved original, original: %s, err: %v", original, err)
+	}
+}
+
+func TestInstallHookRestoresOriginal(t *testing.T) {
+	hook := createTempHook(t)
+	defer os.RemoveAll(hook.Dir)
+	foreign := "#!/bin/sh\nexec lint\n"
+	os.WriteFile(hook.Path(), []byte(foreign), 0755)
+	orig := Executable
+	defer func() { Executable = orig }()
+	Executable = func() (string, error) {
+		return "", fmt.Errorf("planned testing error")
+	}
+
+	set := flag.NewFlagSet("test", 0)
+	set.Bool("force", false, "")
+	if err := installHook(cli.NewContext(nil, set, nil), hook); err == nil {
+		t.Fatal("Installing should return an error when the push-sounds command can't be built")
+	}
+	content, _ := os.ReadFile(hook.Path())
+	if string(content) != foreign || hook.Original() != "" {
+		t.Errorf("The original hook should be put back when installing fails, was:\n%s", content)
+	}
+}
+
+func TestPlayCommandChained(t *testing.T) {
+	orig := Executable
+	defer func() { Executable = orig }()
+	Executable = func() (string, error) {
+		return "/usr/bin/push-sounds", nil
+	}
+
+	command, err := PlayCommand([]string{}, []string{"--libraries", "default", "--from-pre-push", "--failure-libraries", "failure"}, "'/repo/.git/hooks/pre-push.push-sounds-orig'")
+	if err != nil {
+		t.Fatalf("Error building play command: %s", err.Error())
+	}
+	expected := `'/usr/bin/push-sounds' play --libraries 'default' --from-pre-push --failure-libraries 'failure' --chain '/repo/.git/hooks/pre-push.push-sounds-orig' -- "$@" || exit $?`
+	if command != expected {
+		t.Errorf("Expected play command to be:\n\t%s\nwas:\n\t%s", expected, command)
+	}
+}
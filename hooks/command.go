@@ -0,0 +1,272 @@
+package hooks
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jasoncorbett/push-sounds/git"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	GetHooksDir = git.HooksDir
+)
+
+var globalFlag = &cli.BoolFlag{
+	Name:  "global",
+	Usage: "use a push-sounds managed hooks directory set as the global core.hooksPath, covering every repository",
+}
+
+var HooksCommand = &cli.Command{
+	Name:  "hooks",
+	Usage: "manage the git hooks that run push-sounds in the current repository",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "install",
+			Usage:  "Install a pre-push hook that plays a sound, running any existing hook first",
+			Action: installHooks,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:    "libraries",
+					Aliases: []string{"l"},
+					Usage:   "libraries the hook should pull a sound from, instead of the configured ones",
+				},
+				&cli.StringSliceFlag{
+					Name:  "failure-libraries",
+					Usage: "libraries to pull a sound from when an existing hook fails the push, instead of the configured ones",
+				},
+				&cli.BoolFlag{
+					Name:  "after-push",
+					Usage: "also install a reference-transaction hook, and only play once the remote accepted the push",
+				},
+				&cli.BoolFlag{
+					Name:  "detach",
+					Usage: "play in the background so pushes don't wait for the sound",
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "replace an existing hook that was not installed by push-sounds instead of chaining to it",
+				},
+				globalFlag,
+			},
+		},
+		{
+			Name:   "uninstall",
+			Usage:  "Remove the hooks installed by push-sounds, restoring any original hooks",
+			Action: uninstallHooks,
+			Flags:  []cli.Flag{globalFlag},
+		},
+		{
+			Name:   "status",
+			Usage:  "Show whether the push-sounds hooks are installed",
+			Action: hookStatus,
+			Flags:  []cli.Flag{globalFlag},
+		},
+	},
+}
+
+func hooksDir(c *cli.Context) (string, error) {
+	if c.Bool("global") {
+		return GlobalHooksDir(), nil
+	}
+	dir, err := GetHooksDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the hooks directory: %s", err.Error())
+	}
+	return dir, nil
+}
+
+// changedHooksDir is the hooks directory that install and uninstall change.  Without --global it mustn't be the
+// global push-sounds hooks directory that core.hooksPath points every repository at, since changing it for one
+// repository would change it for them all.
+func changedHooksDir(c *cli.Context) (string, error) {
+	dir, err := hooksDir(c)
+	if err != nil {
+		return "", err
+	}
+	if !c.Bool("global") && filepath.Clean(dir) == filepath.Clean(GlobalHooksDir()) {
+		return "", fmt.Errorf("the hooks directory %s is the global push-sounds hooks directory, use --global to change it", dir)
+	}
+	return dir, nil
+}
+
+// installedHooks are the hooks push-sounds runs from, depending on when it should play.
+func installedHooks(c *cli.Context) []string {
+	if c.Bool("after-push") {
+		return []string{PrePush, ReferenceTransaction}
+	}
+	return []string{PrePush}
+}
+
+// playArgs are the play arguments for a hook, chained is true when the hook runs an original hook first.
+// Libraries are only passed when they were given, so changes to the config apply without reinstalling.
+func playArgs(c *cli.Context, name string, chained bool) []string {
+	args := []string{}
+	for _, library := range c.StringSlice("libraries") {
+		args = append(args, "--libraries", library)
+	}
+	switch name {
+	case PrePush:
+		args = append(args, "--from-pre-push")
+		if c.Bool("after-push") {
+			args = append(args, "--after-push")
+		}
+		if chained {
+			for _, library := range c.StringSlice("failure-libraries") {
+				args = append(args, "--failure-libraries", library)
+			}
+		}
+	case ReferenceTransaction:
+		args = append(args, "--from-reference-transaction")
+	}
+	if c.Bool("detach") {
+		args = append(args, "--detach")
+	}
+	return args
+}
+
+func installHooks(c *cli.Context) error {
+	dir, err := changedHooksDir(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("global") {
+		commands := map[string]string{}
+		for _, name := range installedHooks(c) {
+			command, err := PlayCommand(GlobalArgs(c), playArgs(c, name, true), localHook(name))
+			if err != nil {
+				return err
+			}
+			commands[name] = command
+		}
+		if err := InstallGlobal(commands, c.Bool("force")); err != nil {
+			return err
+		}
+		fmt.Printf("Installed %s and set it as the global core.hooksPath\n", dir)
+		return nil
+	}
+	for _, name := range installedHooks(c) {
+		if err := installHook(c, Hook{Dir: dir, Name: name}); err != nil {
+			return err
+		}
+	}
+	if !c.Bool("after-push") {
+		// switching back from playing after the push
+		if _, err := (Hook{Dir: dir, Name: ReferenceTransaction}).Uninstall(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func installHook(c *cli.Context, hook Hook) error {
+	original, moved, err := hook.PreserveOriginal(c.Bool("force"))
+	if err != nil {
+		return err
+	}
+	if err := installChained(c, hook, original); err != nil {
+		// the user's hook goes back where it was rather than being left without a hook chaining to it
+		if moved {
+			if restoreErr := hook.RestoreOriginal(); restoreErr != nil {
+				return fmt.Errorf("%s, and %s", err.Error(), restoreErr.Error())
+			}
+		}
+		return err
+	}
+	fmt.Printf("Installed %s\n", hook.Path())
+	if original != "" {
+		fmt.Printf("Chained to original hook %s\n", original)
+	}
+	return nil
+}
+
+// installChained installs the push-sounds hook, chaining to the original hook if there is one.
+func installChained(c *cli.Context, hook Hook, original string) error {
+	chain := ""
+	if original != "" {
+		chain = shellQuote(original)
+	}
+	command, err := PlayCommand(GlobalArgs(c), playArgs(c, hook.Name, original != ""), chain)
+	if err != nil {
+		return err
+	}
+	return hook.Install(command, c.Bool("force"))
+}
+
+func uninstallHooks(c *cli.Context) error {
+	dir, err := changedHooksDir(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("global") {
+		removed, err := UninstallGlobal()
+		if err != nil {
+			return err
+		}
+		if removed {
+			fmt.Printf("Removed global hooks from %s\n", dir)
+		} else {
+			fmt.Printf("No global push-sounds hooks installed at %s\n", dir)
+		}
+		return nil
+	}
+	for _, name := range []string{PrePush, ReferenceTransaction} {
+		hook := Hook{Dir: dir, Name: name}
+		removed, err := hook.Uninstall()
+		if err != nil {
+			return err
+		}
+		if removed && hook.Exists() {
+			fmt.Printf("Removed push-sounds from %s\n", hook.Path())
+			if original := hook.Original(); original != "" {
+				fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s has other commands, so the original hook %s is left for you to restore\n", hook.Path(), original)
+			}
+		} else if removed {
+			fmt.Printf("Removed %s\n", hook.Path())
+		} else if name == PrePush {
+			fmt.Printf("No push-sounds hook installed at %s\n", hook.Path())
+		}
+	}
+	return nil
+}
+
+func hookStatus(c *cli.Context) error {
+	dir, err := hooksDir(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("global") {
+		hooksPath, ours, err := GlobalHooksPath()
+		if err != nil {
+			return err
+		}
+		switch {
+		case hooksPath == "":
+			fmt.Printf("%-22s %s\n", "core.hooksPath", "not set globally")
+		case ours:
+			fmt.Printf("%-22s %s (managed by push-sounds)\n", "core.hooksPath", hooksPath)
+		default:
+			fmt.Printf("%-22s %s (not managed by push-sounds)\n", "core.hooksPath", hooksPath)
+		}
+	}
+	fmt.Printf("%-22s %s\n", "Hooks directory", dir)
+	for _, name := range []string{PrePush, ReferenceTransaction} {
+		hook := Hook{Dir: dir, Name: name}
+		state, block, err := hook.State()
+		if err != nil {
+			return err
+		}
+		if c.Bool("global") && state == Installed && block == forwardCommand(name) {
+			fmt.Printf("%-22s %s\n", name, "forwarded to the repository's hook")
+			continue
+		}
+		fmt.Printf("%-22s %s\n", name, state.Name())
+		if state == Installed {
+			fmt.Printf("%-22s %s\n", "  Command", block)
+		}
+		if original := hook.Original(); original != "" {
+			fmt.Printf("%-22s %s\n", "  Chained to", original)
+		}
+	}
+	return nil
+}
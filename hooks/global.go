@@ -0,0 +1,119 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/git"
+)
+
+var (
+	// ForwardedHooks are the hooks the global hooks directory passes on to the repository's own hooks, since
+	// setting core.hooksPath stops git from looking in .git/hooks.
+	ForwardedHooks = []string{
+		"applypatch-msg",
+		"pre-applypatch",
+		"post-applypatch",
+		"pre-commit",
+		"pre-merge-commit",
+		"prepare-commit-msg",
+		"commit-msg",
+		"post-commit",
+		"pre-rebase",
+		"post-checkout",
+		"post-merge",
+		"pre-receive",
+		"update",
+		"proc-receive",
+		"post-receive",
+		"post-update",
+		"reference-transaction",
+		"push-to-checkout",
+		"pre-auto-gc",
+		"post-rewrite",
+		"sendemail-validate",
+		"post-index-change",
+	}
+)
+
+// GlobalHooksDir is the push-sounds managed directory that the global core.hooksPath points to.
+func GlobalHooksDir() string {
+	return config.UserConfigPath("push-sounds-hooks")
+}
+
+// localHook is a shell word for a hook in the current repository's own hooks directory.
+func localHook(name string) string {
+	return fmt.Sprintf(`"$(git rev-parse --git-common-dir)/hooks/%s"`, name)
+}
+
+func forwardCommand(name string) string {
+	return fmt.Sprintf("hook=%s\nif [ -x \"$hook\" ]; then\n\texec \"$hook\" \"$@\"\nfi", localHook(name))
+}
+
+// GlobalHooksPath returns the global core.hooksPath and whether it is the push-sounds managed directory.
+func GlobalHooksPath() (string, bool, error) {
+	hooksPath, err := git.ConfigValue("--global", "--path", "core.hooksPath")
+	if err != nil {
+		return "", false, err
+	}
+	ours := hooksPath != "" && filepath.Clean(hooksPath) == filepath.Clean(GlobalHooksDir())
+	return hooksPath, ours, nil
+}
+
+// InstallGlobal writes the push-sounds hooks, keyed by hook name, and forwarding hooks for every other hook into
+// the global hooks directory, then points the global core.hooksPath at it.  A core.hooksPath set to another
+// directory is only replaced when force is true.
+func InstallGlobal(commands map[string]string, force bool) error {
+	hooksPath, ours, err := GlobalHooksPath()
+	if err != nil {
+		return err
+	}
+	if hooksPath != "" && !ours && !force {
+		return fmt.Errorf("global core.hooksPath is already set to %s, use --force to replace it", hooksPath)
+	}
+	dir := GlobalHooksDir()
+	for _, name := range append([]string{PrePush}, ForwardedHooks...) {
+		command, managed := commands[name]
+		if !managed {
+			command = forwardCommand(name)
+		}
+		if err := (Hook{Dir: dir, Name: name}).Install(command, force); err != nil {
+			return err
+		}
+	}
+	if !ours {
+		if _, err := git.Run("config", "--global", "core.hooksPath", dir); err != nil {
+			return fmt.Errorf("unable to set global core.hooksPath: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// UninstallGlobal removes the push-sounds managed hooks from the global hooks directory, and unsets the global
+// core.hooksPath if it points there.  It returns false if there was nothing to remove.
+func UninstallGlobal() (bool, error) {
+	removed := false
+	dir := GlobalHooksDir()
+	for _, name := range append([]string{PrePush}, ForwardedHooks...) {
+		hookRemoved, err := Hook{Dir: dir, Name: name}.Uninstall()
+		if err != nil {
+			return removed, err
+		}
+		removed = removed || hookRemoved
+	}
+	// only succeeds once the directory is empty
+	os.Remove(dir)
+	_, ours, err := GlobalHooksPath()
+	if err != nil {
+		return removed, err
+	}
+	if ours {
+		if _, err := git.Run("config", "--global", "--unset", "core.hooksPath"); err != nil {
+			return removed, fmt.Errorf("unable to unset global core.hooksPath: %s", err.Error())
+		}
+		removed = true
+	}
+	return removed, nil
+}
@@ -0,0 +1,152 @@
+package hooks
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/git"
+	"github.com/urfave/cli/v2"
+)
+
+// setupGlobal points the global git config and the user config directory at a temporary directory.
+func setupGlobal(t *testing.T) (string, func()) {
+	base, err := os.MkdirTemp("", "temp-global-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	origConfigBase := config.GetUserConfigBase
+	origGitConfig, hadGitConfig := os.LookupEnv("GIT_CONFIG_GLOBAL")
+	config.GetUserConfigBase = func() (string, error) {
+		return base, nil
+	}
+	os.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(base, "gitconfig"))
+	return base, func() {
+		config.GetUserConfigBase = origConfigBase
+		if hadGitConfig {
+			os.Setenv("GIT_CONFIG_GLOBAL", origGitConfig)
+		} else {
+			os.Unsetenv("GIT_CONFIG_GLOBAL")
+		}
+		os.RemoveAll(base)
+	}
+}
+
+func TestInstallGlobal(t *testing.T) {
+	base, cleanup := setupGlobal(t)
+	defer cleanup()
+
+	err := InstallGlobal(map[string]string{PrePush: "push-sounds play"}, false)
+	if err != nil {
+		t.Fatalf("Error installing global hooks: %s", err.Error())
+	}
+	hooksPath, ours, err := GlobalHooksPath()
+	if err != nil {
+		t.Fatalf("Error reading global hooks path: %s", err.Error())
+	}
+	expected := filepath.Join(base, "push-sounds-hooks")
+	if hooksPath != expected || !ours {
+		t.Errorf("Global core.hooksPath should be '%s' and managed by push-sounds, was '%s' (ours: %t)", expected, hooksPath, ours)
+	}
+	for _, name := range append([]string{PrePush}, ForwardedHooks...) {
+		state, _, _ := Hook{Dir: expected, Name: name}.State()
+		if state != Installed {
+			t.Errorf("Global hook %s should have been installed, was: %s", name, state.Name())
+		}
+	}
+
+	removed, err := UninstallGlobal()
+	if err != nil || !removed {
+		t.Fatalf("Uninstalling global hooks should remove them, removed: %t, err: %v", removed, err)
+	}
+	hooksPath, _, _ = GlobalHooksPath()
+	if hooksPath != "" {
+		t.Errorf("Global core.hooksPath should have been unset, was '%s'", hooksPath)
+	}
+	if _, err := os.Stat(expected); !os.IsNotExist(err) {
+		t.Errorf("Global hooks directory should have been removed")
+	}
+}
+
+func TestInstallGlobalRefusesForeignHooksPath(t *testing.T) {
+	_, cleanup := setupGlobal(t)
+	defer cleanup()
+	if _, err := git.Run("config", "--global", "core.hooksPath", "/somewhere/else"); err != nil {
+		t.Fatalf("Unable to set global core.hooksPath: %s", err.Error())
+	}
+
+	err := InstallGlobal(map[string]string{PrePush: "push-sounds play"}, false)
+	if err == nil {
+		t.Fatal("Installing global hooks over another core.hooksPath should have returned an error")
+	}
+	err = InstallGlobal(map[string]string{PrePush: "push-sounds play"}, true)
+	if err != nil {
+		t.Fatalf("Forcing a global install should replace core.hooksPath: %s", err.Error())
+	}
+	_, ours, _ := GlobalHooksPath()
+	if !ours {
+		t.Errorf("Global core.hooksPath should be managed by push-sounds after a forced install")
+	}
+}
+
+func TestForwardedHookRunsLocalHook(t *testing.T) {
+	base, cleanup := setupGlobal(t)
+	defer cleanup()
+	repo := filepath.Join(base, "repo")
+	if out, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("unable to initialize temporary repository: %s", out)
+	}
+	os.WriteFile(filepath.Join(repo, ".git", "hooks", "post-commit"), []byte("#!/bin/sh\necho local \"$@\"\n"), 0755)
+
+	if err := InstallGlobal(map[string]string{PrePush: "push-sounds play"}, false); err != nil {
+		t.Fatalf("Error installing global hooks: %s", err.Error())
+	}
+	cmd := exec.Command(filepath.Join(GlobalHooksDir(), "post-commit"), "arg")
+	cmd.Dir = repo
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Forwarding hook failed: %s: %s", err.Error(), out)
+	}
+	if strings.TrimSpace(string(out)) != "local arg" {
+		t.Errorf("Forwarding hook should have run the local hook, output: %#v", string(out))
+	}
+
+	cmd = exec.Command(filepath.Join(GlobalHooksDir(), "pre-commit"))
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("Forwarding hook without a local hook should succeed: %s: %s", err.Error(), out)
+	}
+}
+
+func TestInstallRefusesGlobalHooksDir(t *testing.T) {
+	_, cleanup := setupGlobal(t)
+	defer cleanup()
+	if err := InstallGlobal(map[string]string{PrePush: "push-sounds play"}, false); err != nil {
+		t.Fatalf("Error installing global hooks: %s", err.Error())
+	}
+	orig := GetHooksDir
+	defer func() { GetHooksDir = orig }()
+	// git follows the global core.hooksPath into the push-sounds managed directory
+	GetHooksDir = func() (string, error) {
+		return GlobalHooksDir(), nil
+	}
+	hook := Hook{Dir: GlobalHooksDir(), Name: PrePush}
+	_, before, _ := hook.State()
+
+	set := flag.NewFlagSet("test", 0)
+	set.Bool("global", false, "")
+	c := cli.NewContext(nil, set, nil)
+	if err := installHooks(c); err == nil {
+		t.Errorf("Installing without --global should refuse the global hooks directory")
+	}
+	if err := uninstallHooks(c); err == nil {
+		t.Errorf("Uninstalling without --global should refuse the global hooks directory")
+	}
+	if state, after, _ := hook.State(); state != Installed || after != before {
+		t.Errorf("The global hook should be left as it was, was %s:\n%s", state.Name(), after)
+	}
+}
@@ -3,20 +3,26 @@ package libraries
 import (
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
+
+	"github.com/jasoncorbett/push-sounds/selection"
+)
+
+const (
+	// AttributionExtension is added to the name of a sound for the file crediting where it came from.
+	AttributionExtension = ".attribution"
 )
 
 var (
 	NewSoundLibrary = newSoundLibrary
-	seeded          = false
 )
 
 type SoundLibrary interface {
 	ListLibraries() ([]string, error)
-	GetRandomFile(from []string) (string, error)
+	// Candidates lists the files in the libraries, for a selection.Selector to choose from.
+	Candidates(from []selection.WeightedLibrary) []selection.Candidate
 	ListFiles(library string) ([]string, error)
 }
 
@@ -54,23 +60,18 @@ func (l *directoryBasedSoundLibrary) ListLibraries() ([]string, error) {
 	return libraries, nil
 }
 
-func (l *directoryBasedSoundLibrary) GetRandomFile(from []string) (string, error) {
-	files := []string{}
+func (l *directoryBasedSoundLibrary) Candidates(from []selection.WeightedLibrary) []selection.Candidate {
+	candidates := []selection.Candidate{}
 	for _, library := range from {
-		libraryFiles, err := l.ListFiles(library)
-		if err == nil {
-			files = append(files, libraryFiles...)
+		files, err := l.ListFiles(library.Name)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			candidates = append(candidates, selection.Candidate{File: file, Library: library.Name, Weight: library.Weight})
 		}
 	}
-	if len(files) == 0 {
-		return "", fmt.Errorf("no files available in %v", from)
-	}
-	if !seeded {
-		rand.Seed(time.Now().Unix())
-		seeded = true
-	}
-	randIndex := rand.Intn(len(files))
-	return files[randIndex], nil
+	return candidates
 }
 
 func (l *directoryBasedSoundLibrary) ListFiles(library string) ([]string, error) {
@@ -81,10 +82,19 @@ func (l *directoryBasedSoundLibrary) ListFiles(library string) ([]string, error)
 	}
 	libraryFiles := []string{}
 	for _, libraryFile := range files {
-		if !libraryFile.IsDir() {
+		if !libraryFile.IsDir() && !strings.HasSuffix(libraryFile.Name(), AttributionExtension) {
 			libraryFiles = append(libraryFiles, filepath.Join(libraryPath, libraryFile.Name()))
 		}
 	}
 	return libraryFiles, nil
 
 }
+
+// Attribution is who to credit for a sound, from the attribution file beside it, or empty when it doesn't have one.
+func Attribution(file string) string {
+	content, err := os.ReadFile(file + AttributionExtension)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
@@ -6,11 +6,14 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/jasoncorbett/push-sounds/selection"
 )
 
 var (
 	tempLibrary = []string{
 		"a/b.wav",
+		"a/b.wav.attribution",
 		"c/d.mp3",
 		"c/e.ogg",
 		"f.txt",
@@ -155,6 +158,20 @@ func TestDirectoryBasedSoundLibrary_ListFiles(t *testing.T) {
 	}
 }
 
+func TestAttribution(t *testing.T) {
+	basePath, err := createTempLibrary()
+	defer removeTempLibrary(basePath)
+	if err != nil {
+		t.Fatalf("Unable to create temporary library: %s", err.Error())
+	}
+	if attribution := Attribution(filepath.Join(basePath, "a", "b.wav")); attribution != "test content" {
+		t.Errorf("The attribution for b.wav should come from b.wav.attribution, was '%s'", attribution)
+	}
+	if attribution := Attribution(filepath.Join(basePath, "c", "d.mp3")); attribution != "" {
+		t.Errorf("A sound without an attribution file should have no attribution, was '%s'", attribution)
+	}
+}
+
 func TestDirectoryBasedSoundLibrary_ListFilesLibraryDoesNotExist(t *testing.T) {
 	basePath, err := createTempLibrary()
 	defer removeTempLibrary(basePath)
@@ -196,7 +213,7 @@ func TestDirectoryBasedSoundLibrary_ListFilesErrorDirectoryRemoved(t *testing.T)
 
 }
 
-func TestDirectoryBasedSoundLibrary_GetRandomFile(t *testing.T) {
+func TestDirectoryBasedSoundLibrary_RandomCandidate(t *testing.T) {
 	basePath, err := createTempLibrary()
 	defer removeTempLibrary(basePath)
 	if err != nil {
@@ -214,16 +231,16 @@ func TestDirectoryBasedSoundLibrary_GetRandomFile(t *testing.T) {
 		t.Fatalf("Library 'a' should only contain 1 file, contains: %#v", files)
 	}
 
-	randomFile, err := soundLib.GetRandomFile([]string{"a"})
+	randomFile, err := chooseRandom(soundLib, []string{"a"}, selection.NewRandom(""))
 	if err != nil {
 		t.Errorf("Error getting random file from library 'a': %s", err.Error())
 	}
 	if randomFile != files[0] {
-		t.Errorf("GetRandomFile(['a']) should have returned %s, instead: %s", files[0], randomFile)
+		t.Errorf("Choosing from ['a'] should have returned %s, instead: %s", files[0], randomFile)
 	}
 }
 
-func TestDirectoryBasedSoundLibrary_GetRandomFileDoesNotRepeatOften(t *testing.T) {
+func TestDirectoryBasedSoundLibrary_RandomCandidateDoesNotRepeatOften(t *testing.T) {
 	basePath, err := createTempLibrary()
 	defer removeTempLibrary(basePath)
 	if err != nil {
@@ -245,27 +262,34 @@ func TestDirectoryBasedSoundLibrary_GetRandomFileDoesNotRepeatOften(t *testing.T
 	if len(allfiles) < 3 {
 		t.Fatalf("For random file test to work, there should be at least 3 files: %#v", allfiles)
 	}
-	first, err := soundLib.GetRandomFile([]string{"a", "c"})
+	// each pick takes the next index, so every file comes up once before any repeats
+	next := 0
+	random := selection.RandomFunc(func(n int) int {
+		i := next % n
+		next++
+		return i
+	})
+	first, err := chooseRandom(soundLib, []string{"a", "c"}, random)
 	if err != nil {
 		t.Fatalf("Error encountered when getting random file: %s", err.Error())
 	}
-	second, err := soundLib.GetRandomFile([]string{"a", "c"})
+	second, err := chooseRandom(soundLib, []string{"a", "c"}, random)
 	if err != nil {
 		t.Fatalf("Error encountered when getting random file: %s", err.Error())
 	}
-	third, err := soundLib.GetRandomFile([]string{"a", "c"})
+	third, err := chooseRandom(soundLib, []string{"a", "c"}, random)
 	if err != nil {
 		t.Fatalf("Error encountered when getting random file: %s", err.Error())
 	}
-	if first == second && second == third {
-		t.Errorf("Getting 3 random files in a row should not have produced the same result: %s", first)
+	if first == second || second == third || first == third {
+		t.Errorf("Getting 3 random files in a row should have produced different results: %s, %s, %s", first, second, third)
 	}
 	if !listContains(allfiles, first) || !listContains(allfiles, second) || !listContains(allfiles, third) {
 		t.Errorf("Both the first, second, and third response should have been in the list of all files.  First: %s; Second: %s; Third: %s; All Files: %#v", first, second, third, allfiles)
 	}
 }
 
-func TestDirectoryBasedSoundLibrary_GetRandomFileNonExistingLibrary(t *testing.T) {
+func TestDirectoryBasedSoundLibrary_RandomCandidateNonExistingLibrary(t *testing.T) {
 	basePath, err := createTempLibrary()
 	defer removeTempLibrary(basePath)
 	if err != nil {
@@ -275,16 +299,16 @@ func TestDirectoryBasedSoundLibrary_GetRandomFileNonExistingLibrary(t *testing.T
 	if err != nil {
 		t.Fatalf("Error creating sound library for testing: %s", err.Error())
 	}
-	randomFile, err := soundLib.GetRandomFile([]string{"doesnotexist"})
+	randomFile, err := chooseRandom(soundLib, []string{"doesnotexist"}, selection.NewRandom(""))
 	if err == nil {
-		t.Errorf("GetRandomFile(['doesnotexist']) should have returned and error, but didn't.  RandomFile: %s", randomFile)
+		t.Errorf("Choosing from ['doesnotexist'] should have returned and error, but didn't.  RandomFile: %s", randomFile)
 	}
 	if randomFile != "" {
-		t.Errorf("GetRandomFile(['doesnotexist']) should have returned and empty string, instead: %s", randomFile)
+		t.Errorf("Choosing from ['doesnotexist'] should have returned and empty string, instead: %s", randomFile)
 	}
 }
 
-func TestDirectoryBasedSoundLibrary_GetRandomFileIncludingNonExistingLibrary(t *testing.T) {
+func TestDirectoryBasedSoundLibrary_RandomCandidateIncludingNonExistingLibrary(t *testing.T) {
 	basePath, err := createTempLibrary()
 	defer removeTempLibrary(basePath)
 	if err != nil {
@@ -301,16 +325,16 @@ func TestDirectoryBasedSoundLibrary_GetRandomFileIncludingNonExistingLibrary(t *
 	if len(files) != 1 {
 		t.Fatalf("Library 'a' should only contain 1 file, contains: %#v", files)
 	}
-	randomFile, err := soundLib.GetRandomFile([]string{"a", "doesnotexist"})
+	randomFile, err := chooseRandom(soundLib, []string{"a", "doesnotexist"}, selection.NewRandom(""))
 	if err != nil {
 		t.Fatalf("Getting a random file from a valid library and a non-existing one should not return error but did: %s", err.Error())
 	}
 	if randomFile != files[0] {
-		t.Errorf("GetRandomFile(['a', 'doesnotexist']) should have returned %s, instead: %s", files[0], randomFile)
+		t.Errorf("Choosing from ['a', 'doesnotexist'] should have returned %s, instead: %s", files[0], randomFile)
 	}
 }
 
-func TestDirectoryBasedSoundLibrary_GetRandomFileRemovedBasePath(t *testing.T) {
+func TestDirectoryBasedSoundLibrary_RandomCandidateRemovedBasePath(t *testing.T) {
 	basePath, err := createTempLibrary()
 	// still defer, just in case we error before removing temp library
 	defer removeTempLibrary(basePath)
@@ -322,7 +346,7 @@ func TestDirectoryBasedSoundLibrary_GetRandomFileRemovedBasePath(t *testing.T) {
 		t.Fatalf("Error creating sound library for testing: %s", err.Error())
 	}
 	removeTempLibrary(basePath)
-	randomFile, err := soundLib.GetRandomFile([]string{"a", "c"})
+	randomFile, err := chooseRandom(soundLib, []string{"a", "c"}, selection.NewRandom(""))
 	if err == nil {
 		t.Errorf("Getting a random file from a removed base-path did not create and error, randomFile: %s", randomFile)
 	}
@@ -330,3 +354,70 @@ func TestDirectoryBasedSoundLibrary_GetRandomFileRemovedBasePath(t *testing.T) {
 		t.Errorf("Getting a random file from a removed base-path should have returned an empty string, but returned: %s", randomFile)
 	}
 }
+
+// chooseRandom chooses a file from the candidates in the libraries the way the random strategy does.
+func chooseRandom(soundLib SoundLibrary, from []string, random selection.Random) (string, error) {
+	weighted := []selection.WeightedLibrary{}
+	for _, library := range from {
+		weighted = append(weighted, selection.WeightedLibrary{Name: library, Weight: 1})
+	}
+	selector, _ := selection.New(selection.RandomStrategy, selection.Options{Random: random})
+	return selector.Select(soundLib.Candidates(weighted))
+}
+
+// chooseWeighted chooses a file from the candidates in the libraries the way the weighted strategy does.
+func chooseWeighted(soundLib SoundLibrary, from []selection.WeightedLibrary, mode selection.Mode, random selection.Random) (string, error) {
+	selector, _ := selection.New(selection.WeightedStrategy, selection.Options{Random: random, Mode: mode})
+	return selector.Select(soundLib.Candidates(from))
+}
+
+// fixedIndexes makes the random index always the highest it can be, or the lowest when low is true.
+func fixedIndexes(low bool) selection.Random {
+	return selection.RandomFunc(func(n int) int {
+		if low {
+			return 0
+		}
+		return n - 1
+	})
+}
+
+func TestDirectoryBasedSoundLibrary_WeightedCandidate(t *testing.T) {
+	basePath, err := createTempLibrary()
+	defer removeTempLibrary(basePath)
+	if err != nil {
+		t.Fatalf("Unable to create temporary library: %s", err.Error())
+	}
+	soundLib, err := NewSoundLibrary(basePath)
+	if err != nil {
+		t.Fatalf("Error creating sound library for testing: %s", err.Error())
+	}
+	aFiles, _ := soundLib.ListFiles("a")
+	cFiles, _ := soundLib.ListFiles("c")
+
+	// a has 1 file and c has 2, so the lowest pick is always a and the highest is always the last file in c
+	cases := []struct {
+		From     []selection.WeightedLibrary
+		Mode     selection.Mode
+		Low      bool
+		Expected string
+	}{
+		{[]selection.WeightedLibrary{{Name: "a", Weight: 1}, {Name: "c", Weight: 1}}, selection.ByLibrary, true, aFiles[0]},
+		{[]selection.WeightedLibrary{{Name: "a", Weight: 1}, {Name: "c", Weight: 1}}, selection.ByLibrary, false, cFiles[1]},
+		{[]selection.WeightedLibrary{{Name: "a", Weight: 0}, {Name: "c", Weight: 1}}, selection.ByLibrary, true, cFiles[0]},
+		{[]selection.WeightedLibrary{{Name: "a", Weight: 3}, {Name: "c", Weight: 0}}, selection.ByFile, false, aFiles[0]},
+		{[]selection.WeightedLibrary{{Name: "missing", Weight: 5}, {Name: "a", Weight: 1}}, selection.ByFile, false, aFiles[0]},
+	}
+	for _, c := range cases {
+		actual, err := chooseWeighted(soundLib, c.From, c.Mode, fixedIndexes(c.Low))
+		if err != nil {
+			t.Errorf("Error getting a weighted file from %#v: %s", c.From, err.Error())
+		}
+		if actual != c.Expected {
+			t.Errorf("Expected %#v with mode %s to choose %s, chose %s", c.From, c.Mode.Name(), c.Expected, actual)
+		}
+	}
+
+	if _, err := chooseWeighted(soundLib, []selection.WeightedLibrary{{Name: "a", Weight: 0}, {Name: "missing", Weight: 1}}, selection.ByLibrary, fixedIndexes(true)); err == nil {
+		t.Errorf("Getting a weighted file when no library can be chosen should return an error")
+	}
+}
@@ -1,18 +1,9 @@
 package libraries
 
 import (
-	"os"
-	"path/filepath"
-)
-
-var (
-	GetUserConfigBase = os.UserConfigDir
+	"github.com/jasoncorbett/push-sounds/config"
 )
 
 func GetLocationDefault() string {
-	configDir, err := GetUserConfigBase()
-	if err != nil {
-		configDir = "."
-	}
-	return filepath.Join(configDir, "push-sounds")
+	return config.UserConfigPath("push-sounds")
 }
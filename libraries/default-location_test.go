@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
+
+	"github.com/jasoncorbett/push-sounds/config"
 )
 
 type mockUserConfigBase struct {
@@ -19,12 +21,12 @@ func (m *mockUserConfigBase) fakeUserConfigBase() (string, error) {
 }
 
 func (m *mockUserConfigBase) Replace() {
-	m.orig = GetUserConfigBase
-	GetUserConfigBase = m.fakeUserConfigBase
+	m.orig = config.GetUserConfigBase
+	config.GetUserConfigBase = m.fakeUserConfigBase
 }
 
 func (m *mockUserConfigBase) Restore() {
-	GetUserConfigBase = m.orig
+	config.GetUserConfigBase = m.orig
 }
 
 func TestLocationDefaultHappyPath(t *testing.T) {
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/ratings"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -26,8 +29,17 @@ var ListCommand = &cli.Command{
 	},
 }
 
+// libraryBase is the library base from the flag, environment or config files.
+func libraryBase(c *cli.Context) string {
+	settings, err := config.LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	return settings.String(c, "library-base")
+}
+
 func listLibraries(c *cli.Context) error {
-	lib, err := NewSoundLibrary(c.String("library-base"))
+	lib, err := NewSoundLibrary(libraryBase(c))
 	if err != nil {
 		return fmt.Errorf("unable to initialize sound library: %s", err.Error())
 	}
@@ -56,10 +68,14 @@ func listFilesInLibrary(c *cli.Context) error {
 	if c.Args().Len() == 0 {
 		return fmt.Errorf("required library name to list")
 	}
-	lib, err := NewSoundLibrary(c.String("library-base"))
+	lib, err := NewSoundLibrary(libraryBase(c))
 	if err != nil {
 		return fmt.Errorf("unable to initialize sound library: %s", err.Error())
 	}
+	rated, err := ratings.Load()
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
 	for _, libraryName := range c.Args().Slice() {
 		fmt.Printf("%s\n%s\n", libraryName, strings.Repeat("=", len(libraryName)))
 		files, err := lib.ListFiles(libraryName)
@@ -68,7 +84,7 @@ func listFilesInLibrary(c *cli.Context) error {
 				fmt.Printf("No files found\n\n")
 			} else {
 				for _, file := range files {
-					fmt.Println(filepath.Base(file))
+					fmt.Println(filepath.Base(file) + rated.For(file).Marks())
 				}
 				fmt.Println()
 			}
@@ -4,8 +4,14 @@ import (
 	"log"
 	"os"
 
+	"github.com/jasoncorbett/push-sounds/achievements"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/hooks"
 	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/mute"
 	"github.com/jasoncorbett/push-sounds/play"
+	"github.com/jasoncorbett/push-sounds/ratings"
+	"github.com/jasoncorbett/push-sounds/stats"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,13 +23,39 @@ func main() {
 				Name:    "library-base",
 				Aliases: []string{"lib", "l"},
 				Value:   libraries.GetLocationDefault(),
-				Usage:   "The base directory for libraries.  Each library will be a directory under this folder.",
-				EnvVars: []string{"PUSH_SOUNDS_LIBRARY"},
+				Usage:   "The base directory for libraries.  Each library will be a directory under this folder.  [$PUSH_SOUNDS_LIBRARY]",
+			},
+			&cli.PathFlag{
+				Name:    "config",
+				Value:   config.GetLocationDefault(),
+				Usage:   "The push-sounds config file.",
+				EnvVars: []string{"PUSH_SOUNDS_CONFIG"},
+			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "The profile to take settings from, instead of the one in use, winning over the repo and git config too.  [$PUSH_SOUNDS_PROFILE]",
 			},
 		},
 		Commands: []*cli.Command{
 			play.PlayCommand,
+			play.GitCommand,
+			play.LastCommand,
+			play.AgainCommand,
+			play.StatusCommand,
+			mute.MuteCommand,
+			mute.UnmuteCommand,
+			mute.SnoozeCommand,
+			play.RulesCommand,
 			libraries.ListCommand,
+			hooks.HooksCommand,
+			config.ConfigCommand,
+			config.ProfileCommand,
+			stats.StatsCommand,
+			achievements.AchievementsCommand,
+			ratings.RateCommand,
+			ratings.BanCommand,
+			ratings.UnbanCommand,
+			ratings.FavoriteCommand,
 		},
 	}
 
@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	selection "github.com/jasoncorbett/push-sounds/selection"
 )
 
 // MockSoundLibrary is a mock of SoundLibrary interface.
@@ -33,19 +34,18 @@ func (m *MockSoundLibrary) EXPECT() *MockSoundLibraryMockRecorder {
 	return m.recorder
 }
 
-// GetRandomFile mocks base method.
-func (m *MockSoundLibrary) GetRandomFile(from []string) (string, error) {
+// Candidates mocks base method.
+func (m *MockSoundLibrary) Candidates(from []selection.WeightedLibrary) []selection.Candidate {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRandomFile", from)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Candidates", from)
+	ret0, _ := ret[0].([]selection.Candidate)
+	return ret0
 }
 
-// GetRandomFile indicates an expected call of GetRandomFile.
-func (mr *MockSoundLibraryMockRecorder) GetRandomFile(from interface{}) *gomock.Call {
+// Candidates indicates an expected call of Candidates.
+func (mr *MockSoundLibraryMockRecorder) Candidates(from interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRandomFile", reflect.TypeOf((*MockSoundLibrary)(nil).GetRandomFile), from)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Candidates", reflect.TypeOf((*MockSoundLibrary)(nil).Candidates), from)
 }
 
 // ListFiles mocks base method.
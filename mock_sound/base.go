@@ -6,6 +6,7 @@ package mock_sound
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	sound "github.com/jasoncorbett/push-sounds/sound"
@@ -34,6 +35,20 @@ func (m *MockSound) EXPECT() *MockSoundMockRecorder {
 	return m.recorder
 }
 
+// Duration mocks base method.
+func (m *MockSound) Duration() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Duration")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Duration indicates an expected call of Duration.
+func (mr *MockSoundMockRecorder) Duration() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Duration", reflect.TypeOf((*MockSound)(nil).Duration))
+}
+
 // Location mocks base method.
 func (m *MockSound) Location() string {
 	m.ctrl.T.Helper()
@@ -62,6 +77,42 @@ func (mr *MockSoundMockRecorder) Play() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Play", reflect.TypeOf((*MockSound)(nil).Play))
 }
 
+// SetMaxDuration mocks base method.
+func (m *MockSound) SetMaxDuration(max time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxDuration", max)
+}
+
+// SetMaxDuration indicates an expected call of SetMaxDuration.
+func (mr *MockSoundMockRecorder) SetMaxDuration(max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxDuration", reflect.TypeOf((*MockSound)(nil).SetMaxDuration), max)
+}
+
+// SetVolume mocks base method.
+func (m *MockSound) SetVolume(percent int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetVolume", percent)
+}
+
+// SetVolume indicates an expected call of SetVolume.
+func (mr *MockSoundMockRecorder) SetVolume(percent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVolume", reflect.TypeOf((*MockSound)(nil).SetVolume), percent)
+}
+
+// Stop mocks base method.
+func (m *MockSound) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockSoundMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockSound)(nil).Stop))
+}
+
 // Type mocks base method.
 func (m *MockSound) Type() sound.AudioFileType {
 	m.ctrl.T.Helper()
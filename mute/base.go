@@ -0,0 +1,61 @@
+package mute
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+const (
+	stateFile = "mute.json"
+)
+
+// State is whether sounds are muted, and until when.
+type State struct {
+	Muted bool `json:"muted,omitempty"`
+	// Until is when a snooze ends, sounds stay muted until unmuted when it is zero.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Active is true when sounds are muted at now.
+func (s State) Active(now time.Time) bool {
+	return s.Muted && (s.Until.IsZero() || now.Before(s.Until))
+}
+
+// Describe says how long sounds are muted for.
+func (s State) Describe() string {
+	if s.Until.IsZero() {
+		return "muted until unmuted"
+	}
+	return fmt.Sprintf("snoozed until %s", s.Until.Local().Format("2006-01-02 15:04"))
+}
+
+func statePath() (string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, stateFile), nil
+}
+
+// Load reads whether sounds are muted, they aren't when it has never been saved.
+func Load() (State, error) {
+	path, err := statePath()
+	if err != nil {
+		return State{}, err
+	}
+	saved := State{}
+	err = state.Load(path, &saved)
+	return saved, err
+}
+
+// Save keeps the mute state for the pushes that follow.
+func Save(muted State) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	return state.Save(path, muted)
+}
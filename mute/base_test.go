@@ -0,0 +1,67 @@
+package mute
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+var now = time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+
+func TestStateActive(t *testing.T) {
+	cases := []struct {
+		State  State
+		Active bool
+	}{
+		{State{}, false},
+		{State{Muted: true}, true},
+		{State{Muted: true, Until: now.Add(time.Minute)}, true},
+		{State{Muted: true, Until: now}, false},
+	}
+	for _, c := range cases {
+		if c.State.Active(now) != c.Active {
+			t.Errorf("Expected %+v to be active %t", c.State, c.Active)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	statetest.UseTemp(t)
+	origNow := Now
+	defer func() {
+		Now = origNow
+	}()
+	Now = func() time.Time {
+		return now
+	}
+	var out bytes.Buffer
+	app := &cli.App{Writer: &out, Commands: []*cli.Command{MuteCommand, UnmuteCommand, SnoozeCommand}}
+	cases := []struct {
+		Args     []string
+		Expected State
+		Output   string
+	}{
+		{[]string{"mute"}, State{Muted: true}, "Sounds muted until unmuted"},
+		{[]string{"snooze", "90m"}, State{Muted: true, Until: now.Add(90 * time.Minute)}, "Sounds snoozed until 2024-03-10 13:30"},
+		{[]string{"unmute"}, State{}, "Sounds unmuted"},
+	}
+	for _, c := range cases {
+		out.Reset()
+		if err := app.Run(append([]string{"push-sounds"}, c.Args...)); err != nil {
+			t.Fatalf("%v should not return an error: %s", c.Args, err.Error())
+		}
+		state, _ := Load()
+		if state.Muted != c.Expected.Muted || !state.Until.Equal(c.Expected.Until) || !strings.Contains(out.String(), c.Output) {
+			t.Errorf("%v should save %+v and say '%s', saved %+v and said '%s'", c.Args, c.Expected, c.Output, state, out.String())
+		}
+	}
+	for _, duration := range []string{"", "soon", "-1h"} {
+		if err := app.Run([]string{"push-sounds", "snooze", duration}); err == nil {
+			t.Errorf("Snoozing for '%s' should return an error", duration)
+		}
+	}
+}
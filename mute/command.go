@@ -0,0 +1,61 @@
+package mute
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Now = time.Now
+)
+
+var MuteCommand = &cli.Command{
+	Name:   "mute",
+	Usage:  "Stop playing sounds until unmuted",
+	Action: muteSounds,
+}
+
+var UnmuteCommand = &cli.Command{
+	Name:   "unmute",
+	Usage:  "Play sounds again after mute or snooze",
+	Action: unmuteSounds,
+}
+
+var SnoozeCommand = &cli.Command{
+	Name:      "snooze",
+	Usage:     "Stop playing sounds for a while (e.g. push-sounds snooze 1h)",
+	ArgsUsage: "<duration>",
+	Action:    snoozeSounds,
+}
+
+func muteSounds(c *cli.Context) error {
+	state := State{Muted: true}
+	if err := Save(state); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Sounds %s\n", state.Describe())
+	return nil
+}
+
+func unmuteSounds(c *cli.Context) error {
+	if err := Save(State{}); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.App.Writer, "Sounds unmuted")
+	return nil
+}
+
+func snoozeSounds(c *cli.Context) error {
+	duration, err := time.ParseDuration(c.Args().First())
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("snooze for how long, like 30m or 1h, was '%s'", c.Args().First())
+	}
+	state := State{Muted: true, Until: Now().Add(duration)}
+	if err := Save(state); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Sounds %s\n", state.Describe())
+	return nil
+}
@@ -0,0 +1,48 @@
+package play
+
+import (
+	"fmt"
+
+	"github.com/jasoncorbett/push-sounds/burst"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/urfave/cli/v2"
+)
+
+// throttle keeps a burst of pushes from playing a sound each, with the cooldown and coalesce settings.  The push
+// that opens a coalesce window plays right away and the pushes during it don't, except the one that makes it
+// coalesce-pushes, which plays from the coalesce libraries instead, interrupting the first sound if it is still
+// playing.  No push waits for the window to pass.  Problems with the state shared by the pushes are reported and
+// the sound plays anyway.
+func throttle(c *cli.Context, settings *config.Settings, p plan) (plan, error) {
+	cooldown, err := settings.Lookup(c, "cooldown").Duration()
+	if err != nil {
+		return p, err
+	}
+	window, err := settings.Lookup(c, "coalesce").Duration()
+	if err != nil {
+		return p, err
+	}
+	if cooldown == 0 && window == 0 {
+		return p, nil
+	}
+	many := 0
+	bigger := settings.StringSlice(c, "coalesce-libraries")
+	if len(bigger) > 0 {
+		if many, err = settings.Lookup(c, "coalesce-pushes").Int(); err != nil {
+			return p, err
+		}
+	}
+	decision, err := burst.Arrive(Now(), cooldown, window, many)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+		return p, nil
+	}
+	if !decision.Play {
+		return plan{Choice: p.Choice, Silenced: decision.Silenced}, nil
+	}
+	if decision.Pushes > 1 {
+		p.Choice = choice{Libraries: bigger, Reason: fmt.Sprintf("%d pushes coalesced", decision.Pushes)}
+		p.Replaces = true
+	}
+	return p, nil
+}
@@ -0,0 +1,43 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+var (
+	RunHook = runHook
+)
+
+// runHook runs a git hook script with the arguments and input git gave the push-sounds hook, returning its exit
+// status.  A hook that is missing or not executable is skipped the same way git would skip it.
+func runHook(path string, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0, nil
+	}
+	if stat.IsDir() || stat.Mode().Perm()&0111 == 0 {
+		fmt.Fprintf(stderr, "push-sounds: skipping hook %s since it is not an executable file\n", path)
+		return 0, nil
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() < 1 {
+			// killed by a signal, which git treats as a failed hook
+			return 1, nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, fmt.Errorf("unable to run hook %s: %s", path, err.Error())
+	}
+	return 0, nil
+}
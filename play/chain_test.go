@@ -0,0 +1,49 @@
+package play
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeHook(t *testing.T, script string, mode os.FileMode) (string, func()) {
+	dir, err := os.MkdirTemp("", "temp-hook-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	path := filepath.Join(dir, "pre-push")
+	if err := os.WriteFile(path, []byte(script), mode); err != nil {
+		t.Fatalf("unable to write hook: %s", err.Error())
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestRunHookPassesArgsAndInput(t *testing.T) {
+	path, cleanup := writeHook(t, "#!/bin/sh\necho \"$1 $2\"\ncat\nexit 4\n", 0755)
+	defer cleanup()
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := RunHook(path, []string{"origin", "url"}, strings.NewReader("refs\n"), &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Unexpected error running hook: %s", err.Error())
+	}
+	if exitCode != 4 {
+		t.Errorf("Expected exit code 4 from hook, was %d", exitCode)
+	}
+	if stdout.String() != "origin url\nrefs\n" {
+		t.Errorf("Hook should have received arguments and input, output was: %#v", stdout.String())
+	}
+}
+
+func TestRunHookSkipsNonExecutable(t *testing.T) {
+	path, cleanup := writeHook(t, "#!/bin/sh\nexit 1\n", 0644)
+	defer cleanup()
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := RunHook(path, []string{}, strings.NewReader(""), &stdout, &stderr)
+	if err != nil || exitCode != 0 {
+		t.Errorf("A non-executable hook should be skipped, exit code: %d, err: %v", exitCode, err)
+	}
+}
@@ -0,0 +1,119 @@
+package play
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/git"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/rules"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	GetTopLevel      = git.TopLevel
+	GetCurrentBranch = currentBranch
+	GetRemoteURL     = remoteURL
+	GetHeadCommit    = headCommit
+	// Now is the clock schedule rules are checked against.
+	Now = time.Now
+)
+
+// choice is the libraries a sound will be pulled from, or that no sound should play, and why.
+type choice struct {
+	Libraries []string
+	Mute      bool
+	Reason    string
+}
+
+func (ch choice) Describe() string {
+	if ch.Mute {
+		return fmt.Sprintf("%s: mute", ch.Reason)
+	}
+	return fmt.Sprintf("%s: libraries %s", ch.Reason, strings.Join(ch.Libraries, ", "))
+}
+
+func currentBranch() (string, error) {
+	return git.Run("symbolic-ref", "--short", "-q", "HEAD")
+}
+
+func headCommit() (string, error) {
+	return git.Run("rev-parse", "HEAD")
+}
+
+func remoteURL(remote string) (string, error) {
+	return git.Run("remote", "get-url", remote)
+}
+
+// targetFor describes a push for matching against rules, filling in the current repository and branch when the
+// push doesn't say.  An event without a remote didn't come from a push, so it has no event name.
+func targetFor(event push.Event) rules.Target {
+	target := rules.Target{
+		Branch: event.Branch(),
+		Remote: event.Remote,
+		URL:    event.URL,
+		Time:   Now(),
+	}
+	if event.Remote != "" {
+		target.Event = event.Kind().Name()
+	}
+	if target.Branch == "" {
+		target.Branch, _ = GetCurrentBranch()
+	}
+	target.Repo, _ = GetTopLevel()
+	return target
+}
+
+// chooseLibraries decides where a sound comes from: the first matching rule, then the libraries configured
+// for the kind of push, then the fallback.
+func chooseLibraries(cfg *config.Config, target rules.Target, fallback config.Value) choice {
+	if i := rules.Match(cfg.Rules, target); i >= 0 {
+		rule := cfg.Rules[i]
+		return choice{Libraries: rule.Libraries, Mute: rule.Mute, Reason: rule.Describe(i)}
+	}
+	if target.Event != "" {
+		kind, err := push.ParseEventKind(target.Event)
+		if err == nil && cfg.EventLibraries(kind) != nil {
+			return choice{Libraries: cfg.EventLibraries(kind), Reason: fmt.Sprintf("%s event", target.Event)}
+		}
+	}
+	return settingChoice(fallback)
+}
+
+// settingChoice plays from the libraries in a setting, giving where the setting came from as the reason.
+func settingChoice(value config.Value) choice {
+	return choice{Libraries: value.Values, Reason: fmt.Sprintf("%s from %s", value.Key.Name, value.Source.Name())}
+}
+
+// settingsKey is where the settings are kept on the context once they are loaded.
+type settingsKey struct{}
+
+// settingsFor loads the config files, problems with them are reported and the flags and defaults are used instead.
+// They are loaded once for each run of push-sounds, and kept on the context for the rest of it.
+func settingsFor(c *cli.Context) *config.Settings {
+	if settings, found := c.Context.Value(settingsKey{}).(*config.Settings); found {
+		return settings
+	}
+	settings, err := config.LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	c.Context = context.WithValue(c.Context, settingsKey{}, settings)
+	return settings
+}
+
+// choiceFor chooses the libraries for a push using the config files.
+func choiceFor(c *cli.Context, target rules.Target) choice {
+	settings := settingsFor(c)
+	return chooseLibraries(settings.Config(), target, settings.Lookup(c, "libraries"))
+}
+
+func playChoice(c *cli.Context, ch choice, event push.Event) error {
+	if ch.Mute {
+		return nil
+	}
+	return playFrom(c, ch, event)
+}
@@ -0,0 +1,73 @@
+package play
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/rules"
+)
+
+func TestChooseLibraries(t *testing.T) {
+	cfg := &config.Config{
+		Events: map[string][]string{"tag": {"fanfare"}},
+		Rules: []rules.Rule{
+			{Name: "mirror", Remote: rules.Patterns{"mirror"}, Mute: true},
+			{Name: "release", Branch: rules.Patterns{"release/*"}, Libraries: []string{"trumpets"}},
+		},
+	}
+	cases := []struct {
+		Target   rules.Target
+		Expected choice
+	}{
+		{rules.Target{Remote: "mirror", Event: "tag"}, choice{Libraries: nil, Mute: true, Reason: "rule 1 (mirror)"}},
+		{rules.Target{Branch: "release/1", Remote: "origin", Event: "update"}, choice{Libraries: []string{"trumpets"}, Reason: "rule 2 (release)"}},
+		{rules.Target{Branch: "main", Remote: "origin", Event: "tag"}, choice{Libraries: []string{"fanfare"}, Reason: "tag event"}},
+		{rules.Target{Branch: "main", Remote: "origin", Event: "update"}, choice{Libraries: []string{"default"}, Reason: "libraries from default"}},
+		{rules.Target{Branch: "main"}, choice{Libraries: []string{"default"}, Reason: "libraries from default"}},
+	}
+	key, _ := config.LookupKey("libraries")
+	fallback := config.Value{Key: key, Values: []string{"default"}, Source: config.DefaultSource}
+	for _, c := range cases {
+		actual := chooseLibraries(cfg, c.Target, fallback)
+		if !reflect.DeepEqual(actual, c.Expected) {
+			t.Errorf("Expected %#v to choose %#v, chose %#v", c.Target, c.Expected, actual)
+		}
+	}
+}
+
+func TestChooseLibrariesOnSchedule(t *testing.T) {
+	origNow := Now
+	defer func() {
+		Now = origNow
+	}()
+	cfg := &config.Config{
+		Rules: []rules.Rule{
+			{Name: "halloween", Dates: rules.Dates{"10-20..10-31"}, Libraries: []string{"spooky"}},
+			{Name: "holidays", Dates: rules.Dates{"12-01..12-31"}, Libraries: []string{"holiday"}},
+			{Name: "evening", Times: rules.Times{"18:00.."}, Libraries: []string{"soft"}},
+		},
+	}
+	cases := []struct {
+		Now      time.Time
+		Expected string
+	}{
+		{time.Date(2024, 10, 28, 19, 0, 0, 0, time.Local), "rule 1 (halloween)"},
+		{time.Date(2024, 12, 24, 9, 0, 0, 0, time.Local), "rule 2 (holidays)"},
+		{time.Date(2024, 6, 1, 18, 30, 0, 0, time.Local), "rule 3 (evening)"},
+		{time.Date(2024, 6, 1, 9, 0, 0, 0, time.Local), "libraries from default"},
+	}
+	key, _ := config.LookupKey("libraries")
+	fallback := config.Value{Key: key, Values: []string{"default"}, Source: config.DefaultSource}
+	for _, c := range cases {
+		now := c.Now
+		Now = func() time.Time {
+			return now
+		}
+		if actual := chooseLibraries(cfg, targetFor(push.Event{}), fallback); actual.Reason != c.Expected {
+			t.Errorf("At %s expected %s to choose the libraries, chose %#v", c.Now, c.Expected, actual)
+		}
+	}
+}
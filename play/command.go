@@ -1,11 +1,36 @@
 package play
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/achievements"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/history"
 	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/ratings"
+	"github.com/jasoncorbett/push-sounds/selection"
 	"github.com/jasoncorbett/push-sounds/sound"
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	// WrappedEnv is set by the git wrapper so hooks don't play a second sound for the same push.
+	WrappedEnv = "PUSH_SOUNDS_WRAPPED"
+
+	// a push that takes longer than this to report success is treated as not having happened
+	pendingMaxAge = 10 * time.Minute
+)
+
+var (
+	RecordPush = achievements.Record
+)
+
 var PlayCommand = &cli.Command{
 	Name:   "play",
 	Usage:  "Play one of the sounds from the library",
@@ -14,24 +39,344 @@ var PlayCommand = &cli.Command{
 		&cli.StringSliceFlag{
 			Name:    "libraries",
 			Aliases: []string{"l"},
-			Usage:   "list all the libraries you can pull a sound from",
+			Usage:   "list all the libraries you can pull a sound from, as name or name:weight (e.g. default:1,memes:3)",
 			Value:   cli.NewStringSlice("default"),
 		},
+		&cli.StringFlag{
+			Name:  "strategy",
+			Usage: "how sounds are picked: " + strings.Join(selection.Strategies(), ", "),
+			Value: selection.WeightedStrategy,
+		},
+		&cli.StringFlag{
+			Name:  "seed",
+			Usage: "always make the same choices for the same seed",
+		},
+		&cli.StringFlag{
+			Name:  "seed-from",
+			Usage: "seed the choices from the pushed commit, branch or repo, so the same push always plays the same sound",
+		},
+		&cli.StringFlag{
+			Name:  "selection",
+			Usage: "for the weighted strategy, library picks a library by weight then a file in it, file pools the files of every library",
+			Value: selection.ByLibrary.Name(),
+		},
+		&cli.PathFlag{
+			Name:  "chain",
+			Usage: "run this git hook first with the remaining arguments and exit with its status",
+		},
+		&cli.StringSliceFlag{
+			Name:  "failure-libraries",
+			Usage: "libraries to pull a sound from when the chained hook fails",
+			Value: cli.NewStringSlice("failure"),
+		},
+		&cli.BoolFlag{
+			Name:  "from-pre-push",
+			Usage: "read what is being pushed from the pre-push hook's arguments and input",
+		},
+		&cli.BoolFlag{
+			Name:  "after-push",
+			Usage: "with --from-pre-push, wait for the reference-transaction hook to report the push succeeded before playing",
+		},
+		&cli.BoolFlag{
+			Name:  "from-reference-transaction",
+			Usage: "play for a pending push once the reference-transaction hook reports remote-tracking refs moved",
+		},
+		&cli.StringFlag{
+			Name:  "max-duration",
+			Usage: "fade out the sound if it is still playing after this long, like 5s, 0s plays it to the end",
+		},
+		&cli.StringFlag{
+			Name:  "timeout",
+			Usage: fmt.Sprintf("give up on the sound if playing it takes longer than this altogether, exiting with %d unless chained to a hook", TimeoutExitCode),
+		},
+		&cli.BoolFlag{
+			Name:  "detach",
+			Usage: "play in the background so the push doesn't wait for the sound, errors go to the detached log in the state directory",
+		},
 	},
 }
 
 func playSound(c *cli.Context) error {
-	lib, err := libraries.NewSoundLibrary(c.String("library-base"))
+	var input io.Reader = c.App.Reader
+	var hookInput []byte
+	if c.Bool("from-pre-push") || c.Bool("from-reference-transaction") {
+		// the input is kept so it can be read again by a chained hook
+		var err error
+		hookInput, err = io.ReadAll(c.App.Reader)
+		if err != nil {
+			fmt.Fprintf(c.App.ErrWriter, "push-sounds: unable to read hook input: %s\n", err.Error())
+		}
+		input = bytes.NewReader(hookInput)
+	}
+	if exitCode, detached := detachedExitCode(); detached {
+		// the process that started this one already ran the chained hook, and has nowhere to report errors
+		if err := playOrDetach(c, hookInput, exitCode, false); err != nil {
+			fmt.Fprintf(c.App.ErrWriter, "%s push-sounds: unable to play sound: %s\n", Now().Format(time.RFC3339), err.Error())
+		}
+		return nil
+	}
+	if !c.IsSet("chain") {
+		err := playOrDetach(c, hookInput, 0, c.Bool("detach"))
+		if _, timedOut := err.(timeoutError); timedOut {
+			return cli.Exit("push-sounds: "+err.Error(), TimeoutExitCode)
+		}
+		return err
+	}
+	exitCode, err := RunHook(c.Path("chain"), c.Args().Slice(), input, c.App.Writer, c.App.ErrWriter)
+	if err != nil {
+		return cli.Exit(err.Error(), exitCode)
+	}
+	// the push is decided only by the chained hook, problems playing a sound never change the exit status
+	if err := playOrDetach(c, hookInput, exitCode, c.Bool("detach")); err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: unable to play sound: %s\n", err.Error())
+	}
+	if exitCode != 0 {
+		return cli.Exit("", exitCode)
+	}
+	return nil
+}
+
+// playOrDetach plays for the hook push-sounds is running as, or starts it again in the background to play, once
+// hookPlays decided there is something to play.
+func playOrDetach(c *cli.Context, hookInput []byte, exitCode int, background bool) error {
+	event, plays, err := hookPlays(c, hookInput, exitCode)
+	if err != nil || !plays {
+		return err
+	}
+	if background {
+		return detach(c, hookInput, exitCode)
+	}
+	return withTimeout(c, func() error { return playForHook(c, hookInput, event, exitCode) })
+}
+
+// hookPlays decides whether the hook push-sounds is running as plays a sound, before any settings are loaded, and
+// returns the push it plays for.  A push that waits for the reference-transaction hook is saved as pending here,
+// so it is saved before that hook runs even when the sound would have played in the background.
+func hookPlays(c *cli.Context, hookInput []byte, exitCode int) (push.Event, bool, error) {
+	fromHook := c.Bool("from-pre-push") || c.Bool("from-reference-transaction")
+	if os.Getenv(WrappedEnv) != "" && fromHook {
+		return push.Event{}, false, nil
+	}
+	if c.Bool("from-reference-transaction") {
+		if exitCode != 0 || c.Args().First() != push.Committed {
+			return push.Event{}, false, nil
+		}
+		changes, err := push.ParseReferenceTransaction(bytes.NewReader(hookInput))
+		if err != nil || !push.RemoteTrackingMoved(changes, "") {
+			return push.Event{}, false, err
+		}
+		pending, err := push.HasPending()
+		return push.Event{}, pending, err
+	}
+	event := push.Event{}
+	if c.Bool("from-pre-push") {
+		var err error
+		event, err = push.ParsePrePush(c.Args().Get(0), c.Args().Get(1), bytes.NewReader(hookInput))
+		if err != nil {
+			fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+		}
+	}
+	// pushes that don't move remote-tracking refs never report success, so they play now
+	if exitCode == 0 && c.Bool("after-push") && push.TracksPush(event) {
+		return event, false, push.SavePending(event)
+	}
+	return event, true, nil
+}
+
+// playForHook plays the sound for the push the hook push-sounds is running as plays for.
+func playForHook(c *cli.Context, hookInput []byte, event push.Event, exitCode int) error {
+	if c.Bool("from-reference-transaction") {
+		return playAfterPush(c, hookInput)
+	}
+	ch := choiceFor(c, targetFor(event))
+	if exitCode != 0 {
+		if !ch.Mute {
+			ch = settingChoice(settingsFor(c).Lookup(c, "failure-libraries"))
+		}
+		return playChoice(c, ch, event)
+	}
+	if c.Bool("from-pre-push") {
+		return playPush(c, ch, event)
+	}
+	return playChoice(c, ch, event)
+}
+
+// playAfterPush plays for the pending push once its remote-tracking refs have been updated, which git only does
+// after the remote accepted the push.
+func playAfterPush(c *cli.Context, hookInput []byte) error {
+	changes, err := push.ParseReferenceTransaction(bytes.NewReader(hookInput))
+	if err != nil {
+		return err
+	}
+	event, found, err := push.TakePending(pendingMaxAge, changes)
+	if err != nil || !found {
+		return err
+	}
+	return playPush(c, choiceFor(c, targetFor(event)), event)
+}
+
+// playPush plays for a successful push, celebrating an achievement it earned with a sound from the achievements
+// libraries when there are any.
+func playPush(c *cli.Context, ch choice, event push.Event) error {
+	target := targetFor(event)
+	earned, err := RecordPush(achievements.Push{Repo: target.Repo, Branch: target.Branch, Event: target.Event})
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	if len(earned) == 0 || ch.Mute {
+		return playChoice(c, ch, event)
+	}
+	fmt.Fprintf(c.App.ErrWriter, "push-sounds: achievement earned: %s, %s\n", earned[0].Name, earned[0].Description)
+	celebrate := choice{Libraries: settingsFor(c).StringSlice(c, "achievements"), Reason: "achievement " + earned[0].Name}
+	if !hasSounds(c, celebrate.Libraries) {
+		return playChoice(c, ch, event)
+	}
+	return playFrom(c, celebrate, event)
+}
+
+// hasSounds is true when there is a sound to play in the libraries.
+func hasSounds(c *cli.Context, from []string) bool {
+	weighted, err := selection.ParseLibraries(from)
+	if err != nil {
+		return false
+	}
+	lib, err := libraries.NewSoundLibrary(settingsFor(c).String(c, "library-base"))
+	if err != nil {
+		return false
+	}
+	for _, candidate := range ratedCandidates(c, lib, weighted) {
+		if candidate.Weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// playFrom plays a sound from the libraries of a choice for a push, unless sounds are silenced or it is part of a
+// burst of pushes, see planFor and throttle.
+func playFrom(c *cli.Context, ch choice, event push.Event) error {
+	settings := settingsFor(c)
+	p, err := planFor(c, settings, ch)
+	if err != nil || !p.Play {
+		return err
+	}
+	if p, err = throttle(c, settings, p); err != nil || !p.Play {
+		return err
+	}
+	ch = p.Choice
+	weighted, err := selection.ParseLibraries(ch.Libraries)
 	if err != nil {
 		return err
 	}
-	soundFile, err := lib.GetRandomFile(c.StringSlice("libraries"))
+	lib, err := libraries.NewSoundLibrary(settings.String(c, "library-base"))
 	if err != nil {
 		return err
 	}
-	soundToPlay, err := sound.NewFromFile(soundFile)
+	seed, err := seedFor(c, settings, event)
 	if err != nil {
 		return err
 	}
-	return soundToPlay.Play()
+	chosen, err := chooseFile(c, settings, lib, weighted, seed)
+	if err != nil {
+		return err
+	}
+	soundToPlay, err := sound.NewFromFile(chosen.File)
+	if err != nil {
+		return err
+	}
+	if p.Volume != config.FullVolume {
+		soundToPlay.SetVolume(p.Volume)
+	}
+	if err := limitDuration(c, settings, soundToPlay); err != nil {
+		return err
+	}
+	lock, err := lockPlayback(c, settings, soundToPlay, p.Replaces)
+	if err != nil || lock == nil {
+		return err
+	}
+	started := history.Now()
+	// sounds that fail or are given up on at the timeout aren't recorded as played
+	if err := playUntil(c, lock, soundToPlay); err != nil || c.Context.Err() != nil {
+		return err
+	}
+	if err := recordPlay(chosen, ch, started, soundToPlay.Duration(), event); err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	return nil
+}
+
+// recordPlay adds the sound played for a push to the play history as of when it started, with why it was chosen.
+func recordPlay(chosen selection.Candidate, ch choice, started time.Time, duration time.Duration, event push.Event) error {
+	target := targetFor(event)
+	return history.Record(history.Entry{
+		Time:     started,
+		File:     chosen.File,
+		Library:  chosen.Library,
+		Repo:     target.Repo,
+		Branch:   target.Branch,
+		Event:    target.Event,
+		Reason:   ch.Reason,
+		Duration: duration,
+	})
+}
+
+// ratedCandidates lists the files in the libraries that aren't banned, weighted by their ratings.  Ratings that
+// can't be read are reported and the files play as if unrated.
+func ratedCandidates(c *cli.Context, lib libraries.SoundLibrary, from []selection.WeightedLibrary) []selection.Candidate {
+	rated, err := ratings.Load()
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	return rated.Apply(lib.Candidates(from))
+}
+
+// chooseFile picks the sound to play from the libraries with the strategy setting, the same seed always picks the
+// same sound.
+func chooseFile(c *cli.Context, settings *config.Settings, lib libraries.SoundLibrary, from []selection.WeightedLibrary, seed string) (selection.Candidate, error) {
+	mode, err := selection.ParseMode(settings.String(c, "selection"))
+	if err != nil {
+		return selection.Candidate{}, err
+	}
+	options := selection.Options{
+		Random:      selection.NewRandom(seed),
+		Mode:        mode,
+		LibraryBase: settings.String(c, "library-base"),
+	}
+	strategy := settings.String(c, "strategy")
+	if strategy == selection.HashStrategy {
+		// the hash strategy picks by commit unless it is given something else
+		options.HashKey = seed
+		if options.HashKey == "" {
+			options.HashKey, _ = GetHeadCommit()
+		}
+	}
+	if selection.UsesHistory(strategy) {
+		if options.History, err = history.Load(); err != nil {
+			return selection.Candidate{}, err
+		}
+		if options.RecentPlays, err = settings.Lookup(c, "recent-plays").Int(); err != nil {
+			return selection.Candidate{}, err
+		}
+		if options.RecentHours, err = settings.Lookup(c, "recent-hours").Int(); err != nil {
+			return selection.Candidate{}, err
+		}
+	}
+	selector, err := selection.New(strategy, options)
+	if err != nil {
+		return selection.Candidate{}, err
+	}
+	candidates := ratedCandidates(c, lib, from)
+	if len(candidates) == 0 {
+		return selection.Candidate{}, fmt.Errorf("no files available in %v", from)
+	}
+	file, err := selector.Select(candidates)
+	if err != nil {
+		return selection.Candidate{}, err
+	}
+	for _, candidate := range candidates {
+		if candidate.File == file {
+			return candidate, nil
+		}
+	}
+	return selection.Candidate{File: file}, nil
 }
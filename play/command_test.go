@@ -1,16 +1,55 @@
 package play
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/achievements"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/history"
 	"github.com/jasoncorbett/push-sounds/libraries"
 	"github.com/jasoncorbett/push-sounds/mock_libraries"
 	"github.com/jasoncorbett/push-sounds/mock_sound"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/selection"
 	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
 	"github.com/urfave/cli/v2"
 )
 
+// TestMain keeps the plays made by the tests out of the real history and achievements, and the settings the tests
+// play with away from the real git config, user config and repo config.
+func TestMain(m *testing.M) {
+	stateDir, err := os.MkdirTemp("", "temp-state-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create temporary directory: %s\n", err.Error())
+		os.Exit(1)
+	}
+	statetest.UseDir(stateDir)
+	config.GetGitConfig = func() (*config.Config, error) {
+		return &config.Config{}, nil
+	}
+	config.GetUserConfigBase = func() (string, error) {
+		return stateDir, nil
+	}
+	config.GetTopLevel = func() (string, error) {
+		return "", fmt.Errorf("not in a repository")
+	}
+	// pushes only earn achievements in the tests about them
+	RecordPush = func(achievements.Push) ([]achievements.Achievement, error) {
+		return nil, nil
+	}
+	code := m.Run()
+	os.RemoveAll(stateDir)
+	os.Exit(code)
+}
+
 func createApp(libraryLocation string, libraries ...string) *cli.App {
 	return &cli.App{
 		Flags: []cli.Flag{
@@ -18,6 +57,9 @@ func createApp(libraryLocation string, libraries ...string) *cli.App {
 				Name:  "library-base",
 				Value: libraryLocation,
 			},
+			&cli.PathFlag{
+				Name: "config",
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -28,12 +70,57 @@ func createApp(libraryLocation string, libraries ...string) *cli.App {
 						Name:  "libraries",
 						Value: cli.NewStringSlice(libraries...),
 					},
+					&cli.PathFlag{
+						Name: "chain",
+					},
+					&cli.StringSliceFlag{
+						Name:  "failure-libraries",
+						Value: cli.NewStringSlice("failure"),
+					},
+					&cli.BoolFlag{
+						Name: "from-pre-push",
+					},
+					&cli.BoolFlag{
+						Name: "detach",
+					},
+					&cli.BoolFlag{
+						Name: "after-push",
+					},
+					&cli.BoolFlag{
+						Name: "from-reference-transaction",
+					},
 				},
 			},
 		},
 	}
 }
 
+// newMockSound is a sound that lasts a second, for the history and the playback lock.
+func newMockSound(m *gomock.Controller) *mock_sound.MockSound {
+	ms := mock_sound.NewMockSound(m)
+	ms.EXPECT().Duration().Return(time.Second).AnyTimes()
+	ms.EXPECT().Location().Return("mock-sound").AnyTimes()
+	return ms
+}
+
+// weighted is the libraries with the weight they have when none is given.
+func weighted(names ...string) []selection.WeightedLibrary {
+	weightedLibraries := []selection.WeightedLibrary{}
+	for _, name := range names {
+		weightedLibraries = append(weightedLibraries, selection.WeightedLibrary{Name: name, Weight: 1})
+	}
+	return weightedLibraries
+}
+
+// candidatesFor is a sound in each of the libraries.
+func candidatesFor(names ...string) []selection.Candidate {
+	candidates := []selection.Candidate{}
+	for _, name := range names {
+		candidates = append(candidates, selection.Candidate{File: name + "-sound", Library: name, Weight: 1})
+	}
+	return candidates
+}
+
 func TestPlayCommand(t *testing.T) {
 	randomSoundName := "random-sound"
 	expectedBasePath := "base-library-path"
@@ -47,7 +134,7 @@ func TestPlayCommand(t *testing.T) {
 	// mocks
 	m := gomock.NewController(t)
 	msl := mock_libraries.NewMockSoundLibrary(m)
-	ms := mock_sound.NewMockSound(m)
+	ms := newMockSound(m)
 
 	var actualSoundName *string
 	var actualBasePath *string
@@ -62,8 +149,8 @@ func TestPlayCommand(t *testing.T) {
 
 	msl.
 		EXPECT().
-		GetRandomFile([]string{"default"}).
-		Return(randomSoundName, nil)
+		Candidates(weighted("default")).
+		Return([]selection.Candidate{{File: randomSoundName, Library: "default", Weight: 1}})
 
 	ms.
 		EXPECT().
@@ -83,3 +170,465 @@ func TestPlayCommand(t *testing.T) {
 	}
 
 }
+
+type mockRunHook struct {
+	Path     string
+	Args     []string
+	Input    string
+	ExitCode int
+	Error    error
+	original func(string, []string, io.Reader, io.Writer, io.Writer) (int, error)
+}
+
+func (m *mockRunHook) Mock() {
+	m.original = RunHook
+	RunHook = func(path string, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+		m.Path = path
+		m.Args = args
+		input, _ := io.ReadAll(stdin)
+		m.Input = string(input)
+		return m.ExitCode, m.Error
+	}
+}
+
+func (m *mockRunHook) Restore() {
+	RunHook = m.original
+}
+
+func runChained(t *testing.T, hookExitCode int, expectedLibraries []string, playError error, extraArgs ...string) (*mockRunHook, int) {
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted(expectedLibraries...)).Return(candidatesFor(expectedLibraries...))
+	ms.EXPECT().Play().Return(playError)
+
+	hook := &mockRunHook{ExitCode: hookExitCode}
+	hook.Mock()
+	defer hook.Restore()
+
+	exitCode := 0
+	app := createApp("base", "default")
+	app.Reader = strings.NewReader("refs/heads/main 2222222222222222222222222222222222222222 refs/heads/main 0000000000000000000000000000000000000000\n")
+	app.ExitErrHandler = func(c *cli.Context, err error) {
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	args := append([]string{"test", "play", "--chain", "orig-hook"}, extraArgs...)
+	err := app.Run(append(args, "--", "origin", "git@example.com:repo.git"))
+	if err != nil && hookExitCode == 0 {
+		t.Errorf("Chained play should not return an error when the original hook passed: %s", err.Error())
+	}
+	return hook, exitCode
+}
+
+func TestPlayCommandChainedSuccess(t *testing.T) {
+	hook, exitCode := runChained(t, 0, []string{"default"}, nil)
+	if hook.Path != "orig-hook" {
+		t.Errorf("Expected chained hook 'orig-hook' to be run, but ran '%s'", hook.Path)
+	}
+	if len(hook.Args) != 2 || hook.Args[0] != "origin" || hook.Args[1] != "git@example.com:repo.git" {
+		t.Errorf("Chained hook should have received the hook arguments, received: %#v", hook.Args)
+	}
+	if exitCode != 0 {
+		t.Errorf("Exit code should have been 0 when the chained hook passed, was %d", exitCode)
+	}
+}
+
+func TestPlayCommandChainedFailure(t *testing.T) {
+	_, exitCode := runChained(t, 3, []string{"failure"}, nil)
+	if exitCode != 3 {
+		t.Errorf("Exit code should have been the chained hook's exit code 3, was %d", exitCode)
+	}
+}
+
+func TestPlayCommandChainedIgnoresSoundErrors(t *testing.T) {
+	_, exitCode := runChained(t, 0, []string{"default"}, fmt.Errorf("planned testing error"))
+	if exitCode != 0 {
+		t.Errorf("A sound error should not change the exit code of a chained hook, was %d", exitCode)
+	}
+}
+
+func TestPlayCommandLoadsSettingsOnce(t *testing.T) {
+	orig := config.GetGitConfig
+	defer func() {
+		config.GetGitConfig = orig
+	}()
+	loads := 0
+	config.GetGitConfig = func() (*config.Config, error) {
+		loads++
+		return &config.Config{}, nil
+	}
+	runChained(t, 1, []string{"failure"}, nil, "--from-pre-push")
+	if loads != 1 {
+		t.Errorf("The settings should be loaded once for a push, were loaded %d times", loads)
+	}
+}
+
+func TestPlayCommandChainedFromPrePushPassesInput(t *testing.T) {
+	hook, _ := runChained(t, 0, []string{"default"}, nil, "--from-pre-push")
+	expected := "refs/heads/main 2222222222222222222222222222222222222222 refs/heads/main 0000000000000000000000000000000000000000\n"
+	if hook.Input != expected {
+		t.Errorf("Chained hook should have received the pre-push input after it was parsed, received: %#v", hook.Input)
+	}
+}
+
+func TestPlayCommandEventLibraries(t *testing.T) {
+	configDir, err := os.MkdirTemp("", "temp-config-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	defer os.RemoveAll(configDir)
+	configPath := filepath.Join(configDir, "push-sounds.yaml")
+	os.WriteFile(configPath, []byte("events:\n  tag: [fanfare]\n"), 0644)
+
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted("fanfare")).Return(candidatesFor("fanfare"))
+	msl.EXPECT().Candidates(weighted("default")).Return(candidatesFor("default"))
+	ms.EXPECT().Play().Return(nil).Times(2)
+
+	tagPush := "refs/tags/v1.0 2222222222222222222222222222222222222222 refs/tags/v1.0 0000000000000000000000000000000000000000\n"
+	for _, input := range []string{tagPush, ""} {
+		app := createApp("base", "default")
+		app.Reader = strings.NewReader(input)
+		err = app.Run([]string{"test", "--config", configPath, "play", "--from-pre-push", "--", "origin", "url"})
+		if err != nil {
+			t.Errorf("Unexpected error playing a sound for pre-push input %#v: %s", input, err.Error())
+		}
+	}
+}
+
+func mockGitConfig(gitConfig *config.Config) func() {
+	orig := config.GetGitConfig
+	config.GetGitConfig = func() (*config.Config, error) {
+		return gitConfig, nil
+	}
+	return func() {
+		config.GetGitConfig = orig
+	}
+}
+
+func TestPlayCommandDisabledInGitConfig(t *testing.T) {
+	defer mockGitConfig(&config.Config{Enabled: "false"})()
+	orig_nsl := libraries.NewSoundLibrary
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+	}()
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		t.Fatal("No sound library should be used when push-sounds.enabled is false")
+		return nil, nil
+	}
+
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("Playing while disabled should not return an error: %s", err.Error())
+	}
+}
+
+func TestPlayCommandVolumeFromGitConfig(t *testing.T) {
+	defer mockGitConfig(&config.Config{Volume: "40", Libraries: []string{"quiet"}})()
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted("quiet")).Return(candidatesFor("quiet"))
+	ms.EXPECT().SetVolume(40)
+	ms.EXPECT().Play().Return(nil)
+
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("Recieved error from running fake app, did not expect that: %s", err.Error())
+	}
+}
+
+func TestPlayCommandWeightedLibraries(t *testing.T) {
+	defer mockGitConfig(&config.Config{})()
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	expected := []selection.WeightedLibrary{{Name: "default", Weight: 1}, {Name: "memes", Weight: 3}}
+	msl.EXPECT().Candidates(expected).Return([]selection.Candidate{
+		{File: "default-sound", Library: "default", Weight: 1},
+		{File: "memes-sound", Library: "memes", Weight: 3},
+	})
+	ms.EXPECT().Play().Return(nil)
+
+	os.Setenv("PUSH_SOUNDS_SELECTION", "file")
+	defer os.Unsetenv("PUSH_SOUNDS_SELECTION")
+	app := createApp("base-library-path", "default:1,memes:3")
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("Recieved error from running fake app, did not expect that: %s", err.Error())
+	}
+}
+
+func TestPlayCommandShuffleBag(t *testing.T) {
+	defer mockGitConfig(&config.Config{Strategy: "shuffle-bag"})()
+	cacheDir, err := os.MkdirTemp("", "temp-cache-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	defer os.RemoveAll(cacheDir)
+	orig_cache := selection.GetUserCacheBase
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		selection.GetUserCacheBase = orig_cache
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	selection.GetUserCacheBase = func() (string, error) {
+		return cacheDir, nil
+	}
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	played := []string{}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		played = append(played, soundFile)
+		return ms, nil
+	}
+	msl.EXPECT().Candidates([]selection.WeightedLibrary{{Name: "default", Weight: 1}, {Name: "muted", Weight: 0}}).Return([]selection.Candidate{
+		{File: "one", Library: "default", Weight: 1},
+		{File: "two", Library: "default", Weight: 1},
+		{File: "three", Library: "muted", Weight: 0},
+	}).Times(2)
+	ms.EXPECT().Play().Return(nil).Times(2)
+
+	app := createApp("base-library-path", "default", "muted:0")
+	for i := 0; i < 2; i++ {
+		if err := app.Run([]string{"test", "play"}); err != nil {
+			t.Fatalf("Recieved error from running fake app, did not expect that: %s", err.Error())
+		}
+	}
+	if len(played) != 2 || played[0] == played[1] || played[0] == "three" || played[1] == "three" {
+		t.Errorf("The shuffle bag should play both files from default once, played: %v", played)
+	}
+}
+
+func TestPlayCommandSameSeedSameSound(t *testing.T) {
+	defer mockGitConfig(&config.Config{})()
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	played := []string{}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		played = append(played, soundFile)
+		return ms, nil
+	}
+	files := []selection.Candidate{}
+	for _, file := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		files = append(files, selection.Candidate{File: file, Library: "default", Weight: 1})
+	}
+	msl.EXPECT().Candidates(weighted("default")).Return(files).AnyTimes()
+	ms.EXPECT().Play().Return(nil).AnyTimes()
+
+	os.Setenv("PUSH_SOUNDS_SEED", "demo")
+	defer os.Unsetenv("PUSH_SOUNDS_SEED")
+	app := createApp("base-library-path", "default")
+	for i := 0; i < 5; i++ {
+		if err := app.Run([]string{"test", "play"}); err != nil {
+			t.Fatalf("Recieved error from running fake app, did not expect that: %s", err.Error())
+		}
+	}
+	for _, file := range played {
+		if file != played[0] {
+			t.Fatalf("The same seed should always play the same sound, played: %v", played)
+		}
+	}
+}
+
+func TestPlayCommandRecordsHistory(t *testing.T) {
+	defer mockGitConfig(&config.Config{Strategy: "least-recently-played"})()
+	statetest.UseTemp(t)
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted("default", "memes")).Return(candidatesFor("default", "memes")).Times(3)
+	ms.EXPECT().Play().Return(nil).Times(3)
+
+	app := createApp("base-library-path", "default", "memes")
+	for i := 0; i < 3; i++ {
+		if err := app.Run([]string{"test", "play"}); err != nil {
+			t.Fatalf("Recieved error from running fake app, did not expect that: %s", err.Error())
+		}
+	}
+	entries, err := history.Load()
+	if err != nil {
+		t.Fatalf("Unable to load history: %s", err.Error())
+	}
+	if len(entries) != 3 || entries[0].Library == entries[1].Library || entries[2].File != entries[0].File {
+		t.Fatalf("Least recently played should take turns with the sounds in the history, was %v", entries)
+	}
+	if entries[0].Repo == "" || entries[0].Time.IsZero() || entries[0].Reason != "libraries from default" || entries[0].Duration != time.Second {
+		t.Errorf("The history should record where, when and why each sound played, was %v", entries[0])
+	}
+}
+
+func TestPlayCommandFailedPlayNotRecorded(t *testing.T) {
+	statetest.UseTemp(t)
+	ms, restore := mockPlay(t, "default")
+	defer restore()
+	ms.EXPECT().Play().Return(fmt.Errorf("planned testing error"))
+
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play"}); err == nil {
+		t.Errorf("A sound that fails to play should return an error")
+	}
+	if entries, _ := history.Load(); len(entries) != 0 {
+		t.Errorf("A sound that failed to play should not be in the history, was %v", entries)
+	}
+}
+
+func TestPlayCommandAchievement(t *testing.T) {
+	defer mockGitConfig(&config.Config{})()
+	orig_rp := RecordPush
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		RecordPush = orig_rp
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	var recorded achievements.Push
+	RecordPush = func(p achievements.Push) ([]achievements.Achievement, error) {
+		recorded = p
+		earned, _ := achievements.Find("new-branch")
+		return []achievements.Achievement{earned}, nil
+	}
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	played := []string{}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		played = append(played, soundFile)
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted("achievements")).Return(candidatesFor("achievements")).Times(2)
+	msl.EXPECT().Candidates(weighted("celebrate")).Return([]selection.Candidate{})
+	msl.EXPECT().Candidates(weighted("default")).Return(candidatesFor("default"))
+	ms.EXPECT().Play().Return(nil).Times(2)
+
+	input := "refs/heads/topic 2222222222222222222222222222222222222222 refs/heads/topic 0000000000000000000000000000000000000000\n"
+	var errOut strings.Builder
+	app := createApp("base", "default")
+	app.ErrWriter = &errOut
+	app.Reader = strings.NewReader(input)
+	if err := app.Run([]string{"test", "play", "--from-pre-push", "--", "origin", "git@example.com:repo.git"}); err != nil {
+		t.Fatalf("Recieved error from running fake app, did not expect that: %s", err.Error())
+	}
+	if recorded.Event != "new-branch" || recorded.Branch != "topic" {
+		t.Errorf("The push should be counted towards achievements, was %+v", recorded)
+	}
+	if !strings.Contains(errOut.String(), "achievement earned: new-branch") {
+		t.Errorf("The achievement should be announced, was: %s", errOut.String())
+	}
+	// without any sounds in the achievements libraries the push plays as usual
+	os.Setenv("PUSH_SOUNDS_ACHIEVEMENTS", "celebrate")
+	defer os.Unsetenv("PUSH_SOUNDS_ACHIEVEMENTS")
+	app.Reader = strings.NewReader(input)
+	if err := app.Run([]string{"test", "play", "--from-pre-push", "--", "origin", "git@example.com:repo.git"}); err != nil {
+		t.Fatalf("Recieved error from running fake app, did not expect that: %s", err.Error())
+	}
+	if len(played) != 2 || played[0] != "achievements-sound" || played[1] != "default-sound" {
+		t.Errorf("An achievement should play from the achievements library, played: %v", played)
+	}
+}
+
+func TestPlayCommandAfterPushTagPlaysNow(t *testing.T) {
+	commonDir := t.TempDir()
+	origCommonDir := push.GetCommonDir
+	defer func() {
+		push.GetCommonDir = origCommonDir
+	}()
+	push.GetCommonDir = func() (string, error) {
+		return commonDir, nil
+	}
+	ms, restore := mockPlay(t, "default")
+	defer restore()
+	ms.EXPECT().Play().Return(nil)
+
+	app := createApp("base", "default")
+	app.Reader = strings.NewReader("refs/tags/v1 2222222222222222222222222222222222222222 refs/tags/v1 0000000000000000000000000000000000000000\n")
+	if err := app.Run([]string{"test", "play", "--from-pre-push", "--after-push", "--", "origin", "git@example.com:repo.git"}); err != nil {
+		t.Errorf("A tag push should play straight away: %s", err.Error())
+	}
+	if entries, _ := os.ReadDir(commonDir); len(entries) != 0 {
+		t.Errorf("A tag push should not wait for remote-tracking refs that never move, saved %v", entries)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package play
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setDetached starts cmd in its own session, so it isn't stopped along with the hook that started it.
+func setDetached(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package play
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const (
+	detachedProcess = 0x00000008
+)
+
+// setDetached starts cmd without a console in its own process group, so it isn't stopped along with the hook that
+// started it.
+func setDetached(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+}
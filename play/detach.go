@@ -0,0 +1,109 @@
+package play
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jasoncorbett/push-sounds/state"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// DetachedEnv is set for push-sounds started in the background by --detach, to the exit code of the hook
+	// chained to by the process that started it.
+	DetachedEnv = "PUSH_SOUNDS_DETACHED"
+
+	detachedLog = "detached.log"
+	// the detached log is started over once it is bigger than this
+	maxLogSize = 1 << 20
+)
+
+var (
+	Executable    = os.Executable
+	StartDetached = startDetached
+)
+
+// detachedExitCode is the chained hook's exit code when push-sounds was started in the background by --detach.
+func detachedExitCode() (int, bool) {
+	value, found := os.LookupEnv(DetachedEnv)
+	if !found {
+		return 0, false
+	}
+	exitCode, _ := strconv.Atoi(value)
+	return exitCode, true
+}
+
+// DetachedLogPath is where push-sounds running in the background writes what it would have shown.
+func DetachedLogPath() (string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, detachedLog), nil
+}
+
+func openDetachedLog() (*os.File, error) {
+	path, err := DetachedLogPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("unable to create the state directory: %s", err.Error())
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if stat, err := os.Stat(path); err == nil && stat.Size() > maxLogSize {
+		flags |= os.O_TRUNC
+	}
+	logFile, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the detached log: %s", err.Error())
+	}
+	return logFile, nil
+}
+
+// detach runs push-sounds again with the same arguments in the background, in its own session, to play for the
+// hook so the push doesn't wait for the sound.  It is given the hook's input, and its output goes to the
+// detached log.
+func detach(c *cli.Context, hookInput []byte, exitCode int) error {
+	executable, err := Executable()
+	if err != nil {
+		return fmt.Errorf("unable to find push-sounds to play in the background: %s", err.Error())
+	}
+	logFile, err := openDetachedLog()
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", DetachedEnv, exitCode))
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	setDetached(cmd)
+	if err := StartDetached(cmd, hookInput); err != nil {
+		return fmt.Errorf("unable to play in the background: %s", err.Error())
+	}
+	return nil
+}
+
+// startDetached starts cmd without waiting for it, writing input to it.
+func startDetached(cmd *exec.Cmd, input []byte) error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	cmd.Stdin = reader
+	err = cmd.Start()
+	reader.Close()
+	if err != nil {
+		return err
+	}
+	// the input is read before anything else, so this doesn't wait on the sound
+	if _, err := writer.Write(input); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,168 @@
+package play
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/urfave/cli/v2"
+)
+
+func TestPlayCommandDetach(t *testing.T) {
+	orig_nsl := libraries.NewSoundLibrary
+	origExecutable := Executable
+	origStart := StartDetached
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		Executable = origExecutable
+		StartDetached = origStart
+	}()
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		t.Fatal("No sound library should be used until push-sounds is running in the background")
+		return nil, nil
+	}
+	Executable = func() (string, error) {
+		return "/usr/local/bin/push-sounds", nil
+	}
+	var started *exec.Cmd
+	var startedInput string
+	StartDetached = func(cmd *exec.Cmd, input []byte) error {
+		started = cmd
+		startedInput = string(input)
+		return nil
+	}
+	hook := &mockRunHook{ExitCode: 3}
+	hook.Mock()
+	defer hook.Restore()
+
+	input := "refs/heads/main 2222222222222222222222222222222222222222 refs/heads/main 0000000000000000000000000000000000000000\n"
+	exitCode := 0
+	app := createApp("base", "default")
+	app.Reader = strings.NewReader(input)
+	app.ExitErrHandler = func(c *cli.Context, err error) {
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	app.Run([]string{"test", "play", "--chain", "orig-hook", "--detach", "--from-pre-push", "--", "origin", "git@example.com:repo.git"})
+	if exitCode != 3 {
+		t.Errorf("Exit code should have been the chained hook's exit code 3, was %d", exitCode)
+	}
+	if started == nil {
+		t.Fatalf("push-sounds should have been started in the background")
+	}
+	if started.Path != "/usr/local/bin/push-sounds" || started.Stdout == nil || started.Stdout != started.Stderr {
+		t.Errorf("push-sounds should be started again with its output in the detached log, was %#v", started)
+	}
+	if env := started.Env[len(started.Env)-1]; env != DetachedEnv+"=3" {
+		t.Errorf("The background push-sounds should be told the chained hook's exit code, was %s", env)
+	}
+	if startedInput != input {
+		t.Errorf("The background push-sounds should be given the hook input, was %#v", startedInput)
+	}
+	if path, _ := DetachedLogPath(); !fileExists(path) {
+		t.Errorf("The detached log should have been created at %s", path)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestPlayCommandDetached(t *testing.T) {
+	os.Setenv(DetachedEnv, "3")
+	defer os.Unsetenv(DetachedEnv)
+	hook, exitCode := runChained(t, 0, []string{"failure"}, nil, "--detach")
+	if hook.Path != "" {
+		t.Errorf("The chained hook was run before push-sounds was started in the background, and shouldn't run again")
+	}
+	if exitCode != 0 {
+		t.Errorf("push-sounds in the background should always exit 0, was %d", exitCode)
+	}
+}
+
+func TestPlayCommandDetachedLogsErrors(t *testing.T) {
+	os.Setenv(DetachedEnv, "0")
+	defer os.Unsetenv(DetachedEnv)
+	var errors strings.Builder
+	orig := RunHook
+	orig_nsl := libraries.NewSoundLibrary
+	defer func() {
+		RunHook = orig
+		libraries.NewSoundLibrary = orig_nsl
+	}()
+	RunHook = func(string, []string, io.Reader, io.Writer, io.Writer) (int, error) {
+		t.Fatal("The chained hook shouldn't run again in the background")
+		return 0, nil
+	}
+	app := createApp("base", "default")
+	app.ErrWriter = &errors
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return nil, os.ErrNotExist
+	}
+	if err := app.Run([]string{"test", "play", "--chain", "orig-hook", "--detach"}); err != nil {
+		t.Errorf("push-sounds in the background should log errors instead of returning them: %s", err.Error())
+	}
+	if !strings.Contains(errors.String(), "unable to play sound") {
+		t.Errorf("The error should have been logged, logged: %s", errors.String())
+	}
+}
+
+// mockDetach makes starting push-sounds in the background an error, and the common dir a temporary directory.
+func mockDetach(t *testing.T) (string, func()) {
+	commonDir := t.TempDir()
+	origStart := StartDetached
+	origCommonDir := push.GetCommonDir
+	StartDetached = func(cmd *exec.Cmd, input []byte) error {
+		t.Errorf("push-sounds should not have been started in the background")
+		return nil
+	}
+	push.GetCommonDir = func() (string, error) {
+		return commonDir, nil
+	}
+	return commonDir, func() {
+		StartDetached = origStart
+		push.GetCommonDir = origCommonDir
+	}
+}
+
+func TestPlayCommandDetachSavesPending(t *testing.T) {
+	commonDir, restore := mockDetach(t)
+	defer restore()
+
+	app := createApp("base", "default")
+	app.Reader = strings.NewReader("refs/heads/main 2222222222222222222222222222222222222222 refs/heads/main 0000000000000000000000000000000000000000\n")
+	if err := app.Run([]string{"test", "play", "--detach", "--from-pre-push", "--after-push", "--", "origin", "git@example.com:repo.git"}); err != nil {
+		t.Errorf("Saving a pending push should not return an error: %s", err.Error())
+	}
+	if entries, _ := os.ReadDir(commonDir); len(entries) != 1 {
+		t.Errorf("The pending push should be saved before the pre-push hook returns, saved %v", entries)
+	}
+}
+
+func TestPlayCommandDetachWithoutPending(t *testing.T) {
+	_, restore := mockDetach(t)
+	defer restore()
+	origGitConfig := config.GetGitConfig
+	defer func() {
+		config.GetGitConfig = origGitConfig
+	}()
+	config.GetGitConfig = func() (*config.Config, error) {
+		t.Errorf("The settings should not be loaded without a pending push to play for")
+		return &config.Config{}, nil
+	}
+
+	app := createApp("base", "default")
+	for _, state := range []string{push.Committed, "prepared"} {
+		app.Reader = strings.NewReader("2222222222222222222222222222222222222222 3333333333333333333333333333333333333333 refs/remotes/origin/main\n")
+		if err := app.Run([]string{"test", "play", "--detach", "--from-reference-transaction", "--", state}); err != nil {
+			t.Errorf("The reference-transaction hook should not return an error without a pending push: %s", err.Error())
+		}
+	}
+}
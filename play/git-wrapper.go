@@ -0,0 +1,88 @@
+package play
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	RunGit = runGit
+)
+
+var GitCommand = &cli.Command{
+	Name:      "git",
+	Usage:     "Run git, playing a sound when a push succeeds or fails (e.g. push-sounds git push origin main)",
+	ArgsUsage: "<git arguments>",
+	Description: "Every argument is passed to git, so the libraries played from are the success-libraries and " +
+		"failure-libraries settings.",
+	// git's own options, like -C, belong to git
+	SkipFlagParsing: true,
+	Action:          playAfterGit,
+}
+
+func runGit(args []string) (int, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// let the hooks know a sound will be played once the push is done
+	cmd.Env = append(os.Environ(), WrappedEnv+"=1")
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() < 1 {
+			return 1, nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, fmt.Errorf("unable to run git: %s", err.Error())
+	}
+	return 0, nil
+}
+
+func playAfterGit(c *cli.Context) error {
+	args := c.Args().Slice()
+	exitCode, err := RunGit(args)
+	if err != nil {
+		return cli.Exit(err.Error(), exitCode)
+	}
+	if gitSubcommand(args) == "push" {
+		// the wrapper doesn't know what was pushed, so seeds come from the repository as it is now
+		var err error
+		if exitCode == 0 {
+			err = playPush(c, settingChoice(settingsFor(c).Lookup(c, "success-libraries")), push.Event{})
+		} else {
+			err = playChoice(c, settingChoice(settingsFor(c).Lookup(c, "failure-libraries")), push.Event{})
+		}
+		if err != nil {
+			fmt.Fprintf(c.App.ErrWriter, "push-sounds: unable to play sound: %s\n", err.Error())
+		}
+	}
+	if exitCode != 0 {
+		return cli.Exit("", exitCode)
+	}
+	return nil
+}
+
+// gitSubcommand finds the git command in a list of git arguments, skipping git's own options.
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-C" || args[i] == "-c" || args[i] == "--git-dir" || args[i] == "--work-tree" || args[i] == "--namespace":
+			// these options take the next argument as their value
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			continue
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
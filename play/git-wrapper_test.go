@@ -0,0 +1,114 @@
+package play
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/mock_libraries"
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/urfave/cli/v2"
+)
+
+func TestGitSubcommand(t *testing.T) {
+	cases := map[string][]string{
+		"push":  {"push", "origin", "main"},
+		"fetch": {"-C", "some/dir", "-c", "push.default=current", "--no-pager", "fetch"},
+		"":      {"--version"},
+	}
+	for expected, args := range cases {
+		if actual := gitSubcommand(args); actual != expected {
+			t.Errorf("Expected git subcommand of %#v to be '%s', was '%s'", args, expected, actual)
+		}
+	}
+}
+
+func runGitWrapper(t *testing.T, gitExitCode int, args []string, expectedLibraries []string) int {
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	orig_rg := RunGit
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+		RunGit = orig_rg
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	if expectedLibraries != nil {
+		msl.EXPECT().Candidates(weighted(expectedLibraries...)).Return(candidatesFor(expectedLibraries...))
+		ms.EXPECT().Play().Return(nil)
+	}
+	var gitArgs []string
+	RunGit = func(args []string) (int, error) {
+		gitArgs = args
+		return gitExitCode, nil
+	}
+
+	exitCode := 0
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:  "library-base",
+				Value: "base",
+			},
+		},
+		Commands: []*cli.Command{GitCommand},
+		ExitErrHandler: func(c *cli.Context, err error) {
+			if exitErr, ok := err.(cli.ExitCoder); ok {
+				exitCode = exitErr.ExitCode()
+			}
+		},
+	}
+	app.Run(append([]string{"test", "git"}, args...))
+	if len(gitArgs) != len(args) {
+		t.Errorf("Expected git to be run with %#v, was run with %#v", args, gitArgs)
+	}
+	return exitCode
+}
+
+func TestGitWrapperPushSuccess(t *testing.T) {
+	exitCode := runGitWrapper(t, 0, []string{"push", "--force", "origin", "main"}, []string{"success"})
+	if exitCode != 0 {
+		t.Errorf("Exit code should have been 0 when git succeeded, was %d", exitCode)
+	}
+}
+
+func TestGitWrapperPushFailure(t *testing.T) {
+	exitCode := runGitWrapper(t, 128, []string{"push", "origin", "main"}, []string{"failure"})
+	if exitCode != 128 {
+		t.Errorf("Exit code should have been git's exit code 128, was %d", exitCode)
+	}
+}
+
+func TestGitWrapperNotPush(t *testing.T) {
+	exitCode := runGitWrapper(t, 0, []string{"status"}, nil)
+	if exitCode != 0 {
+		t.Errorf("Exit code should have been 0 when git succeeded, was %d", exitCode)
+	}
+}
+
+func TestGitWrapperPassesGitOptions(t *testing.T) {
+	exitCode := runGitWrapper(t, 0, []string{"-C", "some/dir", "push", "origin", "main"}, []string{"success"})
+	if exitCode != 0 {
+		t.Errorf("Exit code should have been 0 when git succeeded, was %d", exitCode)
+	}
+	if exitCode := runGitWrapper(t, 0, []string{"--version"}, nil); exitCode != 0 {
+		t.Errorf("Exit code should have been 0 when git succeeded, was %d", exitCode)
+	}
+}
+
+func TestGitWrapperLibrariesFromSettings(t *testing.T) {
+	defer mockGitConfig(&config.Config{SuccessLibraries: []string{"cheers"}})()
+	exitCode := runGitWrapper(t, 0, []string{"push"}, []string{"cheers"})
+	if exitCode != 0 {
+		t.Errorf("Exit code should have been 0 when git succeeded, was %d", exitCode)
+	}
+}
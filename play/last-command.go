@@ -0,0 +1,103 @@
+package play
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/history"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/ratings"
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/urfave/cli/v2"
+)
+
+var LastCommand = &cli.Command{
+	Name:   "last",
+	Usage:  "Show the sound that played last, and why it was chosen",
+	Action: showLast,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "ban",
+			Usage: "never play the last sound again",
+		},
+	},
+}
+
+var AgainCommand = &cli.Command{
+	Name:   "again",
+	Usage:  "Play the sound that played last again",
+	Action: playAgain,
+}
+
+// lastPlayed is the latest play in the history, it is an error for nothing to have played yet.
+func lastPlayed() (history.Entry, error) {
+	entry, found, err := history.Last()
+	if err != nil {
+		return entry, err
+	}
+	if !found {
+		return entry, fmt.Errorf("no sound has played yet")
+	}
+	return entry, nil
+}
+
+func showLast(c *cli.Context) error {
+	entry, err := lastPlayed()
+	if err != nil {
+		return err
+	}
+	attribution := libraries.Attribution(entry.File)
+	if attribution == "" {
+		attribution = "unknown"
+	}
+	where := entry.Repo
+	if entry.Branch != "" {
+		where = fmt.Sprintf("%s (%s)", entry.Repo, entry.Branch)
+	}
+	fmt.Fprintf(c.App.Writer, "%-12s %s\n", "File:", entry.File)
+	fmt.Fprintf(c.App.Writer, "%-12s %s\n", "Library:", entry.Library)
+	fmt.Fprintf(c.App.Writer, "%-12s %s\n", "Duration:", entry.Duration.Round(time.Millisecond*100))
+	fmt.Fprintf(c.App.Writer, "%-12s %s\n", "Attribution:", attribution)
+	fmt.Fprintf(c.App.Writer, "%-12s %s\n", "Chosen by:", entry.Reason)
+	fmt.Fprintf(c.App.Writer, "%-12s %s in %s\n", "Played:", entry.Time.Local().Format("2006-01-02 15:04:05"), where)
+	if c.Bool("ban") {
+		if _, err := ratings.Update(entry.File, func(r *ratings.Rating) { r.Banned = true }); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.App.Writer, "%s banned\n", filepath.Base(entry.File))
+	}
+	return nil
+}
+
+// playAgain replays the last sound at the volume setting, replays aren't added to the history.
+func playAgain(c *cli.Context) error {
+	entry, err := lastPlayed()
+	if err != nil {
+		return err
+	}
+	settings, err := config.LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	volume, err := settings.Lookup(c, "volume").Int()
+	if err != nil {
+		return err
+	}
+	soundToPlay, err := sound.NewFromFile(entry.File)
+	if err != nil {
+		return err
+	}
+	if volume != config.FullVolume {
+		soundToPlay.SetVolume(volume)
+	}
+	if err := limitDuration(c, settings, soundToPlay); err != nil {
+		return err
+	}
+	lock, err := lockPlayback(c, settings, soundToPlay, false)
+	if err != nil || lock == nil {
+		return err
+	}
+	return lock.Play(soundToPlay)
+}
@@ -0,0 +1,88 @@
+package play
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/history"
+	"github.com/jasoncorbett/push-sounds/ratings"
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+func lastApp(out *bytes.Buffer) *cli.App {
+	return &cli.App{
+		Writer:    out,
+		ErrWriter: out,
+		Flags: []cli.Flag{
+			&cli.PathFlag{Name: "config"},
+		},
+		Commands: []*cli.Command{LastCommand, AgainCommand},
+	}
+}
+
+func TestLastWithoutHistory(t *testing.T) {
+	statetest.UseTemp(t)
+	var out bytes.Buffer
+	for _, command := range []string{"last", "again"} {
+		if err := lastApp(&out).Run([]string{"push-sounds", command}); err == nil {
+			t.Errorf("%s should return an error when nothing has played", command)
+		}
+	}
+}
+
+func TestLastAndAgain(t *testing.T) {
+	defer mockGitConfig(&config.Config{Volume: "50"})()
+	statetest.UseTemp(t)
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		sound.NewFromFile = orig_nsff
+	}()
+	history.Record(history.Entry{File: "/base/default/old.mp3", Library: "default"})
+	history.Record(history.Entry{
+		Time:     time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local),
+		File:     "/base/memes/airhorn.mp3",
+		Library:  "memes",
+		Repo:     "/src/one",
+		Branch:   "main",
+		Reason:   "rule 2 (branch main)",
+		Duration: 2340 * time.Millisecond,
+	})
+
+	var out bytes.Buffer
+	if err := lastApp(&out).Run([]string{"push-sounds", "last", "--ban"}); err != nil {
+		t.Fatalf("last should not return an error: %s", err.Error())
+	}
+	for _, expected := range []string{"/base/memes/airhorn.mp3", "memes", "2.3s", "unknown", "rule 2 (branch main)", "2024-03-10 12:00:00 in /src/one (main)", "airhorn.mp3 banned"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("last should show '%s', was:\n%s", expected, out.String())
+		}
+	}
+	if rated, _ := ratings.Load(); !rated.For("/base/memes/airhorn.mp3").Banned {
+		t.Errorf("last --ban should ban the last sound")
+	}
+
+	m := gomock.NewController(t)
+	ms := newMockSound(m)
+	var played string
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		played = soundFile
+		return ms, nil
+	}
+	ms.EXPECT().SetVolume(50)
+	ms.EXPECT().Play().Return(nil)
+	if err := lastApp(&out).Run([]string{"push-sounds", "again"}); err != nil {
+		t.Fatalf("again should not return an error: %s", err.Error())
+	}
+	if played != "/base/memes/airhorn.mp3" {
+		t.Errorf("again should play the last sound, played '%s'", played)
+	}
+	if entries, _ := history.Load(); len(entries) != 2 {
+		t.Errorf("Playing again should not be added to the history, was %v", entries)
+	}
+}
@@ -0,0 +1,125 @@
+package play
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/playback"
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// hookPlaybackWait is the longest a hook that isn't detached waits for another sound, unless playback-wait
+	// is set, since the push waits with it.
+	hookPlaybackWait = time.Second
+)
+
+// holdsUpPush checks push-sounds is running as a hook the push waits for.
+func holdsUpPush(c *cli.Context) bool {
+	if _, detached := detachedExitCode(); detached {
+		return false
+	}
+	return c.IsSet("chain") || c.Bool("from-pre-push") || c.Bool("from-reference-transaction")
+}
+
+// lockPlayback takes the machine's playback lock for a sound with the playback settings, interrupting the sound
+// playing when the sound replaces it.  It is nil when the sound is dropped because another one is playing.
+func lockPlayback(c *cli.Context, settings *config.Settings, s sound.Sound, replaces bool) (*playback.Lock, error) {
+	value := settings.Lookup(c, "playback-wait")
+	wait, err := value.Duration()
+	if err != nil {
+		return nil, err
+	}
+	if value.Source == config.DefaultSource && holdsUpPush(c) && wait > hookPlaybackWait {
+		wait = hookPlaybackWait
+	}
+	if deadline, found := c.Context.Deadline(); found && time.Until(deadline) < wait {
+		wait = time.Until(deadline)
+	}
+	policy := settings.String(c, "playback")
+	if replaces {
+		policy = playback.Interrupt
+	}
+	return playback.Acquire(policy, wait, s)
+}
+
+// limitDuration fades a sound out at the max-duration setting, if it is longer than that.
+func limitDuration(c *cli.Context, settings *config.Settings, s sound.Sound) error {
+	max, err := settings.Lookup(c, "max-duration").Duration()
+	if err != nil {
+		return err
+	}
+	if max > 0 && s.Duration() > max {
+		s.SetMaxDuration(max)
+	}
+	return nil
+}
+
+const (
+	// TimeoutExitCode is the exit status of play when it gave up on a sound after the timeout setting.
+	TimeoutExitCode = 124
+	// a sound stopped at the timeout has this long to finish and release the playback lock
+	stopGrace = time.Second
+)
+
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("gave up on the sound after %s", e.timeout)
+}
+
+// withTimeout runs play with a deadline at the timeout setting.  The sound is stopped at the deadline, and given
+// stopGrace to release the playback lock, but play runs apart so push-sounds gives up at the deadline even when
+// loading the sound or opening the audio device hangs.
+func withTimeout(c *cli.Context, play func() error) error {
+	timeout, err := settingsFor(c).Lookup(c, "timeout").Duration()
+	if err != nil {
+		return err
+	}
+	if timeout == 0 {
+		return play()
+	}
+	ctx, cancel := context.WithTimeout(c.Context, timeout)
+	defer cancel()
+	c.Context = ctx
+	done := make(chan error, 1)
+	go func() {
+		done <- play()
+	}()
+	select {
+	case err := <-done:
+		if ctx.Err() == context.DeadlineExceeded {
+			return timeoutError{timeout: timeout}
+		}
+		return err
+	case <-ctx.Done():
+	}
+	select {
+	case <-done:
+	case <-time.After(stopGrace):
+	}
+	return timeoutError{timeout: timeout}
+}
+
+// playUntil plays the sound while holding the lock, stopping it at the deadline from withTimeout.
+func playUntil(c *cli.Context, lock *playback.Lock, s sound.Sound) error {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-c.Context.Done():
+			s.Stop()
+		case <-done:
+		}
+	}()
+	err := lock.Play(s)
+	close(done)
+	<-stopped
+	return err
+}
@@ -0,0 +1,153 @@
+package play
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/mock_libraries"
+	"github.com/jasoncorbett/push-sounds/mock_sound"
+	"github.com/jasoncorbett/push-sounds/playback"
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+// mockPlay makes the sounds played come from a mock library with a sound in each library.
+func mockPlay(t *testing.T, expectedLibraries ...string) (*mock_sound.MockSound, func()) {
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted(expectedLibraries...)).Return(candidatesFor(expectedLibraries...))
+	return ms, func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}
+}
+
+func TestPlayCommandMaxDuration(t *testing.T) {
+	defer mockGitConfig(&config.Config{MaxDuration: "500ms"})()
+	ms, restore := mockPlay(t, "default")
+	defer restore()
+	ms.EXPECT().SetMaxDuration(500 * time.Millisecond)
+	ms.EXPECT().Play().Return(nil)
+
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("Playing a sound cut short should not return an error: %s", err.Error())
+	}
+}
+
+// runTimeout plays a sound that doesn't finish until it is stopped with a timeout, returning the exit code.
+func runTimeout(t *testing.T, args ...string) int {
+	statetest.UseTemp(t)
+	os.Setenv("PUSH_SOUNDS_TIMEOUT", "50ms")
+	defer os.Unsetenv("PUSH_SOUNDS_TIMEOUT")
+	ms, restore := mockPlay(t, "default")
+	defer restore()
+	stopped := make(chan struct{})
+	ms.EXPECT().Play().DoAndReturn(func() error {
+		<-stopped
+		return nil
+	})
+	ms.EXPECT().Stop().Do(func() {
+		close(stopped)
+	})
+
+	exitCode := 0
+	app := createApp("base-library-path", "default")
+	app.ExitErrHandler = func(c *cli.Context, err error) {
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	app.Run(append([]string{"test", "play"}, args...))
+	if lock, err := playback.Acquire(playback.Drop, 0, ms); err != nil || lock == nil {
+		t.Errorf("The sound given up on should have released the playback lock, got %v (err: %v)", lock, err)
+	}
+	return exitCode
+}
+
+func TestPlayCommandTimeout(t *testing.T) {
+	if exitCode := runTimeout(t); exitCode != TimeoutExitCode {
+		t.Errorf("Play should exit with %d when it times out, exited with %d", TimeoutExitCode, exitCode)
+	}
+}
+
+func TestPlayCommandTimeoutWhileLoading(t *testing.T) {
+	os.Setenv("PUSH_SOUNDS_TIMEOUT", "50ms")
+	defer os.Unsetenv("PUSH_SOUNDS_TIMEOUT")
+	_, restore := mockPlay(t, "default")
+	defer restore()
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		close(loading)
+		<-release
+		return nil, fmt.Errorf("planned testing error")
+	}
+	defer close(release)
+
+	exitCode := 0
+	app := createApp("base-library-path", "default")
+	app.ExitErrHandler = func(c *cli.Context, err error) {
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	start := time.Now()
+	app.Run([]string{"test", "play"})
+	<-loading
+	if exitCode != TimeoutExitCode {
+		t.Errorf("Play should exit with %d when loading the sound hangs, exited with %d", TimeoutExitCode, exitCode)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("Play should give up on a sound that hangs loading at the timeout, waited %s", waited)
+	}
+}
+
+func TestPlayCommandChainedTimeout(t *testing.T) {
+	hook := &mockRunHook{}
+	hook.Mock()
+	defer hook.Restore()
+	if exitCode := runTimeout(t, "--chain", "orig-hook"); exitCode != 0 {
+		t.Errorf("A timeout should never fail a push the chained hook passed, exited with %d", exitCode)
+	}
+}
+
+func TestPlayCommandChainedWaitsBriefly(t *testing.T) {
+	statetest.UseTemp(t)
+	m := gomock.NewController(t)
+	playing := mock_sound.NewMockSound(m)
+	playing.EXPECT().Duration().Return(time.Minute).AnyTimes()
+	playing.EXPECT().Location().Return("long.mp3").AnyTimes()
+	if _, err := playback.Acquire(playback.Queue, 0, playing); err != nil {
+		t.Fatalf("unable to hold the playback lock: %s", err.Error())
+	}
+	_, restore := mockPlay(t, "default")
+	defer restore()
+	hook := &mockRunHook{}
+	hook.Mock()
+	defer hook.Restore()
+
+	start := time.Now()
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play", "--chain", "orig-hook"}); err != nil {
+		t.Errorf("A sound given up on should never fail a push the chained hook passed: %s", err.Error())
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("A hook should wait only briefly for another sound by default, waited %s", waited)
+	}
+}
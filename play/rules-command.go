@@ -0,0 +1,99 @@
+package play
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/rules"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	atFormat = "2006-01-02 15:04"
+)
+
+var RulesCommand = &cli.Command{
+	Name:  "rules",
+	Usage: "check the rules in the config file that choose libraries for a push",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "test",
+			Usage:  "Show which rule would choose the libraries for a push, without playing anything",
+			Action: testRules,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "branch",
+					Usage: "branch being pushed (defaults to the current branch)",
+				},
+				&cli.StringFlag{
+					Name:  "remote",
+					Usage: "name of the remote being pushed to",
+					Value: "origin",
+				},
+				&cli.StringFlag{
+					Name:  "url",
+					Usage: "url of the remote being pushed to (defaults to the remote's url)",
+				},
+				&cli.StringFlag{
+					Name:  "repo",
+					Usage: "path of the repository being pushed from (defaults to the current repository)",
+				},
+				&cli.StringFlag{
+					Name:  "event",
+					Usage: "kind of push: update, new-branch, tag, delete, or force",
+					Value: push.BranchUpdate.Name(),
+				},
+				&cli.StringFlag{
+					Name:  "at",
+					Usage: "local time of the push, like '2024-10-31 19:30' (defaults to now)",
+				},
+				&cli.StringSliceFlag{
+					Name:    "libraries",
+					Aliases: []string{"l"},
+					Usage:   "libraries play would fall back to",
+					Value:   cli.NewStringSlice("default"),
+				},
+			},
+		},
+	},
+}
+
+func testRules(c *cli.Context) error {
+	if _, err := push.ParseEventKind(c.String("event")); err != nil {
+		return err
+	}
+	target := rules.Target{
+		Branch: c.String("branch"),
+		Remote: c.String("remote"),
+		URL:    c.String("url"),
+		Repo:   c.String("repo"),
+		Event:  c.String("event"),
+		Time:   Now(),
+	}
+	if c.IsSet("at") {
+		at, err := time.ParseInLocation(atFormat, c.String("at"), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid time '%s', expected something like '2024-10-31 19:30'", c.String("at"))
+		}
+		target.Time = at
+	}
+	if target.Branch == "" {
+		target.Branch, _ = GetCurrentBranch()
+	}
+	if target.URL == "" {
+		target.URL, _ = GetRemoteURL(target.Remote)
+	}
+	if target.Repo == "" {
+		target.Repo, _ = GetTopLevel()
+	}
+	fmt.Printf("%-8s %s\n", "Branch", target.Branch)
+	fmt.Printf("%-8s %s\n", "Remote", target.Remote)
+	fmt.Printf("%-8s %s\n", "URL", target.URL)
+	fmt.Printf("%-8s %s\n", "Repo", target.Repo)
+	fmt.Printf("%-8s %s\n", "Event", target.Event)
+	fmt.Printf("%-8s %s\n", "Time", target.Time.Format(atFormat+" Mon"))
+	fmt.Println()
+	fmt.Println(choiceFor(c, target).Describe())
+	return nil
+}
@@ -0,0 +1,48 @@
+package play
+
+import (
+	"fmt"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// SeedFromCommit seeds selection with the commit being pushed.
+	SeedFromCommit = "commit"
+	// SeedFromBranch seeds selection with the branch being pushed.
+	SeedFromBranch = "branch"
+	// SeedFromRepo seeds selection with the path of the repository.
+	SeedFromRepo = "repo"
+)
+
+// seedFor is the seed for choosing a sound for a push: the seed setting when it is set, or else what seed-from
+// says to take from the push.  An empty seed means the choice is random.
+func seedFor(c *cli.Context, settings *config.Settings, event push.Event) (string, error) {
+	if seed := settings.String(c, "seed"); seed != "" {
+		return seed, nil
+	}
+	return seedFrom(settings.String(c, "seed-from"), event)
+}
+
+// seedFrom takes the seed from the push, falling back to the repository when the push doesn't say.
+func seedFrom(from string, event push.Event) (string, error) {
+	var seed string
+	switch from {
+	case "":
+	case SeedFromCommit:
+		if seed = event.Commit(); seed == "" {
+			seed, _ = GetHeadCommit()
+		}
+	case SeedFromBranch:
+		if seed = event.Branch(); seed == "" {
+			seed, _ = GetCurrentBranch()
+		}
+	case SeedFromRepo:
+		seed, _ = GetTopLevel()
+	default:
+		return "", fmt.Errorf("unknown seed-from '%s', expected commit, branch or repo", from)
+	}
+	return seed, nil
+}
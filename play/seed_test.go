@@ -0,0 +1,48 @@
+package play
+
+import (
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/push"
+)
+
+func mockRepository() func() {
+	origHead := GetHeadCommit
+	origBranch := GetCurrentBranch
+	origTopLevel := GetTopLevel
+	GetHeadCommit = func() (string, error) { return "head-sha", nil }
+	GetCurrentBranch = func() (string, error) { return "current", nil }
+	GetTopLevel = func() (string, error) { return "/repo", nil }
+	return func() {
+		GetHeadCommit = origHead
+		GetCurrentBranch = origBranch
+		GetTopLevel = origTopLevel
+	}
+}
+
+func TestSeedFrom(t *testing.T) {
+	defer mockRepository()()
+	event := push.Event{Remote: "origin", Updates: []push.RefUpdate{
+		{LocalRef: "refs/heads/feature", LocalSha: "pushed-sha", RemoteRef: "refs/heads/feature", RemoteSha: push.ZeroSha},
+	}}
+	cases := []struct {
+		From     string
+		Event    push.Event
+		Expected string
+	}{
+		{SeedFromCommit, event, "pushed-sha"},
+		{SeedFromBranch, event, "feature"},
+		{SeedFromRepo, event, "/repo"},
+		{SeedFromCommit, push.Event{}, "head-sha"},
+		{SeedFromBranch, push.Event{}, "current"},
+		{"", event, ""},
+	}
+	for _, c := range cases {
+		if actual, err := seedFrom(c.From, c.Event); err != nil || actual != c.Expected {
+			t.Errorf("Seeding from '%s' should use '%s', was '%s' (err: %v)", c.From, c.Expected, actual, err)
+		}
+	}
+	if _, err := seedFrom("bogus", event); err == nil {
+		t.Errorf("Seeding from something unknown should return an error")
+	}
+}
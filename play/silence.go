@@ -0,0 +1,85 @@
+package play
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/mute"
+	"github.com/jasoncorbett/push-sounds/push"
+	"github.com/jasoncorbett/push-sounds/rules"
+	"github.com/urfave/cli/v2"
+)
+
+// plan is whether a sound plays for a choice right now, where from and how loud, or why it doesn't.
+type plan struct {
+	Play   bool
+	Choice choice
+	Volume int
+	// Silenced is why no sound plays.
+	Silenced string
+	// Replaces is true when the sound takes over from the one playing, whatever the playback setting.
+	Replaces bool
+}
+
+// planFor works out what playing for a choice does right now: nothing when the choice is to mute, sounds are
+// disabled or muted, or it's quiet hours without quiet libraries, and otherwise a sound from the choice or the
+// quiet libraries.
+func planFor(c *cli.Context, settings *config.Settings, ch choice) (plan, error) {
+	if ch.Mute {
+		return plan{Choice: ch, Silenced: ch.Describe()}, nil
+	}
+	enabled := settings.Lookup(c, "enabled")
+	if on, err := enabled.Bool(); err != nil || !on {
+		return plan{Choice: ch, Silenced: fmt.Sprintf("disabled by enabled from %s", enabled.Source.Name())}, err
+	}
+	muted, err := mute.Load()
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	if muted.Active(Now()) {
+		return plan{Choice: ch, Silenced: muted.Describe()}, nil
+	}
+	volume, err := settings.Lookup(c, "volume").Int()
+	if err != nil {
+		return plan{Choice: ch}, err
+	}
+	quietHours := settings.StringSlice(c, "quiet-hours")
+	if len(quietHours) == 0 || !rules.Times(quietHours).Matches(Now()) {
+		return plan{Play: true, Choice: ch, Volume: volume}, nil
+	}
+	reason := fmt.Sprintf("quiet hours %s", strings.Join(quietHours, ", "))
+	quiet := settings.StringSlice(c, "quiet-libraries")
+	if len(quiet) == 0 {
+		return plan{Choice: ch, Silenced: reason}, nil
+	}
+	volume, err = settings.Lookup(c, "quiet-volume").Int()
+	if err != nil {
+		return plan{Choice: ch}, err
+	}
+	return plan{Play: true, Choice: choice{Libraries: quiet, Reason: reason}, Volume: volume}, nil
+}
+
+var StatusCommand = &cli.Command{
+	Name:   "status",
+	Usage:  "Show whether a push here would play a sound right now, and why",
+	Action: showStatus,
+}
+
+func showStatus(c *cli.Context) error {
+	settings := settingsFor(c)
+	target := targetFor(push.Event{})
+	p, err := planFor(c, settings, chooseLibraries(settings.Config(), target, settings.Lookup(c, "libraries")))
+	if err != nil {
+		return err
+	}
+	if !p.Play {
+		fmt.Fprintf(c.App.Writer, "%-10s %s\n", "Sound:", "none")
+		fmt.Fprintf(c.App.Writer, "%-10s %s\n", "Why:", p.Silenced)
+		return nil
+	}
+	fmt.Fprintf(c.App.Writer, "%-10s %s\n", "Sound:", "plays")
+	fmt.Fprintf(c.App.Writer, "%-10s %s\n", "Why:", p.Choice.Describe())
+	fmt.Fprintf(c.App.Writer, "%-10s %d%%\n", "Volume:", p.Volume)
+	return nil
+}
@@ -0,0 +1,225 @@
+package play
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/mock_libraries"
+	"github.com/jasoncorbett/push-sounds/mute"
+	"github.com/jasoncorbett/push-sounds/playback"
+	"github.com/jasoncorbett/push-sounds/selection"
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+func mockNow(now time.Time) func() {
+	orig := Now
+	Now = func() time.Time {
+		return now
+	}
+	return func() {
+		Now = orig
+	}
+}
+
+func TestPlayCommandMuted(t *testing.T) {
+	if err := mute.Save(mute.State{Muted: true}); err != nil {
+		t.Fatalf("Unable to mute: %s", err.Error())
+	}
+	defer mute.Save(mute.State{})
+	orig_nsl := libraries.NewSoundLibrary
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+	}()
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		t.Fatal("No sound library should be used when muted")
+		return nil, nil
+	}
+
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("Playing while muted should not return an error: %s", err.Error())
+	}
+}
+
+func TestPlayCommandQuietHours(t *testing.T) {
+	defer mockNow(time.Date(2024, 3, 10, 23, 30, 0, 0, time.Local))()
+	defer mockGitConfig(&config.Config{QuietHours: []string{"22:00..07:00"}, QuietLibraries: []string{"whisper"}, QuietVolume: "10"})()
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(weighted("whisper")).Return(candidatesFor("whisper"))
+	ms.EXPECT().SetVolume(10)
+	ms.EXPECT().Play().Return(nil)
+
+	app := createApp("base-library-path", "default")
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("Playing in quiet hours should not return an error: %s", err.Error())
+	}
+}
+
+func TestStatus(t *testing.T) {
+	cases := []struct {
+		Config   *config.Config
+		Mute     mute.State
+		Expected []string
+	}{
+		{&config.Config{}, mute.State{}, []string{"plays", "libraries from default", "100%"}},
+		{&config.Config{Enabled: "false"}, mute.State{}, []string{"none", "disabled by enabled from git config"}},
+		{&config.Config{}, mute.State{Muted: true, Until: time.Date(2024, 3, 11, 0, 0, 0, 0, time.Local)}, []string{"none", "snoozed until 2024-03-11 00:00"}},
+		{&config.Config{}, mute.State{Muted: true, Until: time.Date(2024, 3, 10, 23, 0, 0, 0, time.Local)}, []string{"plays"}},
+		{&config.Config{QuietHours: []string{"22:00.."}}, mute.State{}, []string{"none", "quiet hours 22:00.."}},
+		{&config.Config{QuietHours: []string{"22:00.."}, QuietLibraries: []string{"whisper"}}, mute.State{}, []string{"plays", "quiet hours 22:00..", "30%"}},
+		{&config.Config{QuietHours: []string{"06:00..07:00"}}, mute.State{}, []string{"plays", "100%"}},
+	}
+	defer mockNow(time.Date(2024, 3, 10, 23, 30, 0, 0, time.Local))()
+	defer mute.Save(mute.State{})
+	var out bytes.Buffer
+	app := &cli.App{Writer: &out, Commands: []*cli.Command{StatusCommand}}
+	for _, c := range cases {
+		restore := mockGitConfig(c.Config)
+		mute.Save(c.Mute)
+		out.Reset()
+		if err := app.Run([]string{"test", "status"}); err != nil {
+			t.Errorf("Status with %+v should not return an error: %s", c.Config, err.Error())
+		}
+		for _, expected := range c.Expected {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("Status with %+v and %+v should say '%s', said:\n%s", c.Config, c.Mute, expected, out.String())
+			}
+		}
+		restore()
+	}
+}
+
+// runPushes plays for pushes a second apart, returning the libraries of the sounds played.
+func runPushes(t *testing.T, pushes int, gitConfig *config.Config) []string {
+	defer mockGitConfig(gitConfig)()
+	statetest.UseTemp(t)
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	played := []string{}
+	msl.EXPECT().Candidates(gomock.Any()).DoAndReturn(func(from []selection.WeightedLibrary) []selection.Candidate {
+		played = append(played, from[0].Name)
+		return candidatesFor(from[0].Name)
+	}).AnyTimes()
+	ms.EXPECT().Play().Return(nil).AnyTimes()
+
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	app := createApp("base-library-path", "default")
+	for push := 0; push < pushes; push++ {
+		restore := mockNow(now.Add(time.Duration(push) * time.Second))
+		if err := app.Run([]string{"test", "play"}); err != nil {
+			t.Errorf("A coalesced push should not return an error: %s", err.Error())
+		}
+		restore()
+	}
+	return played
+}
+
+func TestPlayCommandCoalesce(t *testing.T) {
+	played := runPushes(t, 2, &config.Config{Coalesce: "5s"})
+	if !reflect.DeepEqual(played, []string{"default"}) {
+		t.Errorf("Two pushes in the coalesce window should play one sound, played %v", played)
+	}
+}
+
+func TestPlayCommandCoalesceManyPushes(t *testing.T) {
+	played := runPushes(t, 4, &config.Config{Coalesce: "5s", CoalescePushes: "3", CoalesceLibraries: []string{"fanfare"}})
+	if !reflect.DeepEqual(played, []string{"default", "fanfare"}) {
+		t.Errorf("The third push in the coalesce window should play from the coalesce libraries, played %v", played)
+	}
+}
+
+func TestPlayCommandCoalesceManyPushesInterrupts(t *testing.T) {
+	defer mockGitConfig(&config.Config{Coalesce: "5s", CoalescePushes: "2", CoalesceLibraries: []string{"fanfare"}, Playback: "drop"})()
+	statetest.UseTemp(t)
+	orig_nsl := libraries.NewSoundLibrary
+	orig_nsff := sound.NewFromFile
+	defer func() {
+		libraries.NewSoundLibrary = orig_nsl
+		sound.NewFromFile = orig_nsff
+	}()
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	ms := newMockSound(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	sound.NewFromFile = func(soundFile string) (sound.Sound, error) {
+		return ms, nil
+	}
+	msl.EXPECT().Candidates(gomock.Any()).DoAndReturn(func(from []selection.WeightedLibrary) []selection.Candidate {
+		return candidatesFor(from[0].Name)
+	}).Times(2)
+	ms.EXPECT().Play().Return(nil).Times(2)
+
+	// the first sound is still playing when the second push arrives
+	first := newMockSound(m)
+	stopped := make(chan struct{})
+	first.EXPECT().Play().DoAndReturn(func() error {
+		<-stopped
+		return nil
+	})
+	first.EXPECT().Stop().Do(func() {
+		close(stopped)
+	})
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	app := createApp("base-library-path", "default")
+	restore := mockNow(now)
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Fatalf("The first push should not return an error: %s", err.Error())
+	}
+	restore()
+	lock, err := playback.Acquire(playback.Queue, 0, first)
+	if err != nil || lock == nil {
+		t.Fatalf("unable to hold the playback lock: %v", err)
+	}
+	finished := make(chan struct{})
+	go func() {
+		lock.Play(first)
+		close(finished)
+	}()
+
+	defer mockNow(now.Add(time.Second))()
+	if err := app.Run([]string{"test", "play"}); err != nil {
+		t.Errorf("The push that coalesces should not return an error: %s", err.Error())
+	}
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Errorf("The sound for coalesced pushes should interrupt the first sound instead of playing after it")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package playback
+
+import (
+	"syscall"
+)
+
+// alive checks the process is still running, a process owned by someone else is running too.
+func alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
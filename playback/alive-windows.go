@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+package playback
+
+import (
+	"syscall"
+)
+
+const processQueryLimitedInformation = 0x1000
+
+// alive checks the process is still running, a process that can't be opened has exited.
+func alive(pid int) bool {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+	var exitCode uint32
+	if err := syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+	// STILL_ACTIVE
+	return exitCode == 259
+}
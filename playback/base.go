@@ -0,0 +1,172 @@
+package playback
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/sound"
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+const (
+	// Queue waits for the sound that is playing to finish.
+	Queue = "queue"
+	// Drop doesn't play when another sound is playing.
+	Drop = "drop"
+	// Interrupt asks the sound that is playing to stop.
+	Interrupt = "interrupt"
+
+	lockFile    = "playback.lock"
+	requestFile = "playback.interrupt"
+	// a sound still holding the lock this long after it should have finished was left by a process that hung
+	staleGrace = 5 * time.Second
+	pollEvery  = 50 * time.Millisecond
+)
+
+var (
+	Now   = time.Now
+	Sleep = time.Sleep
+	Alive = alive
+)
+
+func Policies() []string {
+	return []string{Queue, Drop, Interrupt}
+}
+
+// Holder is the sound playing while holding the lock.
+type Holder struct {
+	Pid  int    `json:"pid"`
+	File string `json:"file"`
+	// Until is when the sound should have finished, after it the lock is stale.
+	Until time.Time `json:"until"`
+}
+
+// Lock is the machine's playback lock, held while one sound plays so that sounds from pushes in two terminals
+// don't fight over the audio device.
+type Lock struct {
+	path    string
+	request string
+	holder  Holder
+}
+
+func paths() (string, string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return "", "", fmt.Errorf("unable to create the state directory: %s", err.Error())
+	}
+	return filepath.Join(stateDir, lockFile), filepath.Join(stateDir, requestFile), nil
+}
+
+// create takes the lock if nobody holds it.  The holder is written beside the lock then linked into place, so
+// nobody ever reads a lock without its holder.
+func create(path string, holder Holder) (bool, error) {
+	content, err := json.Marshal(holder)
+	if err != nil {
+		return false, fmt.Errorf("unable to lock playback: %s", err.Error())
+	}
+	temp := fmt.Sprintf("%s.%d", path, os.Getpid())
+	if err := os.WriteFile(temp, content, 0644); err != nil {
+		return false, fmt.Errorf("unable to lock playback: %s", err.Error())
+	}
+	defer os.Remove(temp)
+	if err := os.Link(temp, path); err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to lock playback: %s", err.Error())
+	}
+	return true, nil
+}
+
+// release removes the lock if holder still holds it.
+func release(path string, holder Holder) {
+	if current, found := readHolder(path); found && current.Pid == holder.Pid && current.Until.Equal(holder.Until) {
+		os.Remove(path)
+	}
+}
+
+func readHolder(path string) (Holder, bool) {
+	holder := Holder{}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return holder, false
+	}
+	// a holder that can't be read is treated as stale
+	json.Unmarshal(content, &holder)
+	return holder, true
+}
+
+// stale checks the holder died or should have finished long ago, so the lock was left behind.
+func stale(holder Holder) bool {
+	return Now().After(holder.Until) || holder.Pid <= 0 || !Alive(holder.Pid)
+}
+
+// Acquire takes the playback lock for a sound, following the policy when another sound holds it and waiting at
+// most wait for it.  It returns nil without an error when the policy is to drop the sound.
+func Acquire(policy string, wait time.Duration, s sound.Sound) (*Lock, error) {
+	path, request, err := paths()
+	if err != nil {
+		return nil, err
+	}
+	holder := Holder{Pid: os.Getpid(), File: s.Location()}
+	deadline := Now().Add(wait)
+	for {
+		holder.Until = Now().Add(s.Duration() + staleGrace)
+		created, err := create(path, holder)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			// an interrupt asked of the last holder is done with
+			os.Remove(request)
+			return &Lock{path: path, request: request, holder: holder}, nil
+		}
+		current, found := readHolder(path)
+		if found && stale(current) {
+			release(path, current)
+			continue
+		}
+		switch policy {
+		case Drop:
+			return nil, nil
+		case Interrupt:
+			if _, err := os.Stat(request); os.IsNotExist(err) {
+				os.WriteFile(request, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644)
+			}
+		case Queue:
+		default:
+			return nil, fmt.Errorf("unknown playback policy '%s', expected one of %v", policy, Policies())
+		}
+		if Now().After(deadline) {
+			return nil, fmt.Errorf("%s is still playing after waiting %s", filepath.Base(current.File), wait)
+		}
+		Sleep(pollEvery)
+	}
+}
+
+// Play plays the sound while holding the lock, stopping it if another push interrupts it, then releases the lock.
+func (l *Lock) Play(s sound.Sound) error {
+	defer release(l.path, l.holder)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(pollEvery):
+			}
+			if _, err := os.Stat(l.request); err == nil {
+				s.Stop()
+				return
+			}
+		}
+	}()
+	return s.Play()
+}
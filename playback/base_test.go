@@ -0,0 +1,140 @@
+package playback
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/mock_sound"
+	"github.com/jasoncorbett/push-sounds/state"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+)
+
+func newMockSound(m *gomock.Controller, duration time.Duration) *mock_sound.MockSound {
+	ms := mock_sound.NewMockSound(m)
+	ms.EXPECT().Duration().Return(duration).AnyTimes()
+	ms.EXPECT().Location().Return("/sounds/default/tada.wav").AnyTimes()
+	return ms
+}
+
+func TestAcquireDrop(t *testing.T) {
+	statetest.UseTemp(t)
+	m := gomock.NewController(t)
+	held, err := Acquire(Queue, time.Second, newMockSound(m, time.Minute))
+	if err != nil || held == nil {
+		t.Fatalf("The first sound should take the lock, got %v (err: %v)", held, err)
+	}
+	if lock, err := Acquire(Drop, time.Second, newMockSound(m, time.Second)); err != nil || lock != nil {
+		t.Errorf("A sound should be dropped while another is playing, got %v (err: %v)", lock, err)
+	}
+}
+
+func TestAcquireQueue(t *testing.T) {
+	statetest.UseTemp(t)
+	m := gomock.NewController(t)
+	first := newMockSound(m, time.Minute)
+	release := make(chan struct{})
+	first.EXPECT().Play().DoAndReturn(func() error {
+		<-release
+		return nil
+	})
+	held, _ := Acquire(Queue, time.Second, first)
+	played := make(chan error)
+	go func() {
+		played <- held.Play(first)
+	}()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+	}()
+	lock, err := Acquire(Queue, 5*time.Second, newMockSound(m, time.Second))
+	if err != nil || lock == nil {
+		t.Errorf("A queued sound should take the lock once the first finishes, got %v (err: %v)", lock, err)
+	}
+	<-played
+}
+
+func TestAcquireQueueTimeout(t *testing.T) {
+	statetest.UseTemp(t)
+	m := gomock.NewController(t)
+	Acquire(Queue, time.Second, newMockSound(m, time.Minute))
+	if _, err := Acquire(Queue, 100*time.Millisecond, newMockSound(m, time.Second)); err == nil {
+		t.Errorf("A queued sound should give up waiting for a sound that is still playing")
+	}
+}
+
+func TestAcquireInterrupt(t *testing.T) {
+	statetest.UseTemp(t)
+	m := gomock.NewController(t)
+	first := newMockSound(m, time.Minute)
+	stopped := make(chan struct{})
+	first.EXPECT().Play().DoAndReturn(func() error {
+		<-stopped
+		return nil
+	})
+	first.EXPECT().Stop().Do(func() {
+		close(stopped)
+	})
+	held, _ := Acquire(Queue, time.Second, first)
+	played := make(chan error)
+	go func() {
+		played <- held.Play(first)
+	}()
+	lock, err := Acquire(Interrupt, 5*time.Second, newMockSound(m, time.Second))
+	if err != nil || lock == nil {
+		t.Errorf("An interrupting sound should take the lock once the first stops, got %v (err: %v)", lock, err)
+	}
+	<-played
+	stateDir, _ := state.Dir()
+	if _, err := os.Stat(filepath.Join(stateDir, requestFile)); !os.IsNotExist(err) {
+		t.Errorf("The interrupt should be cleared once it is done with")
+	}
+}
+
+func TestAcquireStale(t *testing.T) {
+	statetest.UseTemp(t)
+	origNow := Now
+	defer func() {
+		Now = origNow
+	}()
+	m := gomock.NewController(t)
+	Acquire(Queue, time.Second, newMockSound(m, time.Second))
+	// the process playing the first sound died, and it should have finished long ago
+	Now = func() time.Time {
+		return origNow().Add(time.Minute)
+	}
+	if lock, err := Acquire(Drop, time.Second, newMockSound(m, time.Second)); err != nil || lock == nil {
+		t.Errorf("A stale lock should be taken over, got %v (err: %v)", lock, err)
+	}
+}
+
+func TestAcquireDeadHolder(t *testing.T) {
+	statetest.UseTemp(t)
+	origAlive := Alive
+	defer func() {
+		Alive = origAlive
+	}()
+	m := gomock.NewController(t)
+	Acquire(Queue, time.Second, newMockSound(m, time.Minute))
+	if lock, err := Acquire(Drop, time.Second, newMockSound(m, time.Second)); err != nil || lock != nil {
+		t.Errorf("A sound should be dropped while a running process holds the lock, got %v (err: %v)", lock, err)
+	}
+	// the process playing the first sound was killed before it finished
+	Alive = func(pid int) bool {
+		return false
+	}
+	if lock, err := Acquire(Drop, time.Second, newMockSound(m, time.Second)); err != nil || lock == nil {
+		t.Errorf("A lock held by a process that died should be taken over, got %v (err: %v)", lock, err)
+	}
+}
+
+func TestAcquireUnknownPolicy(t *testing.T) {
+	statetest.UseTemp(t)
+	m := gomock.NewController(t)
+	Acquire(Queue, time.Second, newMockSound(m, time.Minute))
+	if _, err := Acquire("shout", time.Second, newMockSound(m, time.Second)); err == nil {
+		t.Errorf("An unknown policy should return an error")
+	}
+}
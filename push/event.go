@@ -0,0 +1,192 @@
+package push
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jasoncorbett/push-sounds/git"
+)
+
+const (
+	ZeroSha = "0000000000000000000000000000000000000000"
+
+	branchPrefix = "refs/heads/"
+	tagPrefix    = "refs/tags/"
+)
+
+var (
+	IsAncestor = isAncestor
+)
+
+type EventKind int
+
+// Event kinds are ordered by how notable they are, when a push contains several kinds of updates the most
+// notable one describes the push.
+const (
+	NoUpdates EventKind = iota
+	BranchUpdate
+	NewBranch
+	TagPush
+	BranchDelete
+	ForcePush
+)
+
+func (k EventKind) Name() string {
+	switch k {
+	case NoUpdates:
+		return "none"
+	case BranchUpdate:
+		return "update"
+	case NewBranch:
+		return "new-branch"
+	case TagPush:
+		return "tag"
+	case BranchDelete:
+		return "delete"
+	case ForcePush:
+		return "force"
+	default:
+		return "unknown"
+	}
+}
+
+func EventKinds() []EventKind {
+	return []EventKind{
+		NoUpdates,
+		BranchUpdate,
+		NewBranch,
+		TagPush,
+		BranchDelete,
+		ForcePush,
+	}
+}
+
+// ParseEventKind looks up an event kind by name.
+func ParseEventKind(name string) (EventKind, error) {
+	for _, kind := range EventKinds() {
+		if kind.Name() == name {
+			return kind, nil
+		}
+	}
+	return NoUpdates, fmt.Errorf("unknown push event '%s'", name)
+}
+
+// RefUpdate is one line of the input git gives the pre-push hook.
+type RefUpdate struct {
+	LocalRef  string
+	LocalSha  string
+	RemoteRef string
+	RemoteSha string
+	Kind      EventKind
+}
+
+// Branch is the short name of the remote branch being updated, or an empty string for other refs.
+func (u RefUpdate) Branch() string {
+	if !strings.HasPrefix(u.RemoteRef, branchPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(u.RemoteRef, branchPrefix)
+}
+
+// Event describes everything being sent to a remote in one push.
+type Event struct {
+	Remote  string
+	URL     string
+	Updates []RefUpdate
+}
+
+func (e Event) Kind() EventKind {
+	kind := NoUpdates
+	for _, update := range e.Updates {
+		if update.Kind > kind {
+			kind = update.Kind
+		}
+	}
+	return kind
+}
+
+// Branch is the first branch being pushed, or an empty string if no branches are being pushed.
+func (e Event) Branch() string {
+	for _, update := range e.Updates {
+		if branch := update.Branch(); branch != "" {
+			return branch
+		}
+	}
+	return ""
+}
+
+// Commit is the first commit being pushed, or an empty string if the push only deletes refs.
+func (e Event) Commit() string {
+	for _, update := range e.Updates {
+		if update.LocalSha != "" && update.LocalSha != ZeroSha {
+			return update.LocalSha
+		}
+	}
+	return ""
+}
+
+// ParsePrePush reads the "<local ref> <local sha> <remote ref> <remote sha>" lines git gives the pre-push hook.
+// The remote and url are the arguments git gives the hook.
+func ParsePrePush(remote string, url string, input io.Reader) (Event, error) {
+	event := Event{
+		Remote:  remote,
+		URL:     url,
+		Updates: []RefUpdate{},
+	}
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return event, fmt.Errorf("invalid pre-push line: %s", line)
+		}
+		update := RefUpdate{
+			LocalRef:  fields[0],
+			LocalSha:  fields[1],
+			RemoteRef: fields[2],
+			RemoteSha: fields[3],
+		}
+		update.Kind = classify(update)
+		event.Updates = append(event.Updates, update)
+	}
+	if err := scanner.Err(); err != nil {
+		return event, fmt.Errorf("unable to read pre-push input: %s", err.Error())
+	}
+	return event, nil
+}
+
+func classify(update RefUpdate) EventKind {
+	switch {
+	case isZeroSha(update.LocalSha):
+		return BranchDelete
+	case strings.HasPrefix(update.RemoteRef, tagPrefix):
+		return TagPush
+	case isZeroSha(update.RemoteSha):
+		return NewBranch
+	}
+	ancestor, err := IsAncestor(update.RemoteSha, update.LocalSha)
+	if err == nil && !ancestor {
+		return ForcePush
+	}
+	// when the remote commit isn't known locally there's no telling if it's a force push
+	return BranchUpdate
+}
+
+func isZeroSha(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+func isAncestor(ancestor string, descendant string) (bool, error) {
+	_, err := git.Run("merge-base", "--is-ancestor", ancestor, descendant)
+	var gitErr *git.Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,171 @@
+package push
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/git"
+)
+
+const (
+	shaA = "1111111111111111111111111111111111111111"
+	shaB = "2222222222222222222222222222222222222222"
+)
+
+type mockIsAncestor struct {
+	Ancestor bool
+	Error    error
+	original func(string, string) (bool, error)
+}
+
+func (m *mockIsAncestor) Mock() {
+	m.original = IsAncestor
+	IsAncestor = func(ancestor string, descendant string) (bool, error) {
+		return m.Ancestor, m.Error
+	}
+}
+
+func (m *mockIsAncestor) Restore() {
+	IsAncestor = m.original
+}
+
+func parseLine(t *testing.T, line string, ancestor bool) Event {
+	mock := &mockIsAncestor{Ancestor: ancestor}
+	mock.Mock()
+	defer mock.Restore()
+	event, err := ParsePrePush("origin", "git@example.com:repo.git", strings.NewReader(line+"\n"))
+	if err != nil {
+		t.Fatalf("Error parsing pre-push line '%s': %s", line, err.Error())
+	}
+	if len(event.Updates) != 1 {
+		t.Fatalf("Expected one update from '%s', got: %#v", line, event.Updates)
+	}
+	return event
+}
+
+func TestParsePrePushKinds(t *testing.T) {
+	cases := []struct {
+		Line     string
+		Ancestor bool
+		Kind     EventKind
+	}{
+		{"refs/heads/main " + shaB + " refs/heads/main " + shaA, true, BranchUpdate},
+		{"refs/heads/main " + shaB + " refs/heads/main " + shaA, false, ForcePush},
+		{"refs/heads/feature " + shaB + " refs/heads/feature " + ZeroSha, true, NewBranch},
+		{"(delete) " + ZeroSha + " refs/heads/feature " + shaA, true, BranchDelete},
+		{"refs/tags/v1.0 " + shaB + " refs/tags/v1.0 " + ZeroSha, true, TagPush},
+	}
+	for _, c := range cases {
+		event := parseLine(t, c.Line, c.Ancestor)
+		if event.Kind() != c.Kind {
+			t.Errorf("Expected '%s' (ancestor: %t) to be a %s push, was %s", c.Line, c.Ancestor, c.Kind.Name(), event.Kind().Name())
+		}
+	}
+}
+
+func TestParsePrePushRemote(t *testing.T) {
+	event := parseLine(t, "refs/heads/main "+shaB+" refs/heads/release/1.0 "+shaA, true)
+	if event.Remote != "origin" || event.URL != "git@example.com:repo.git" {
+		t.Errorf("Remote and url should have come from the hook arguments, was: %s %s", event.Remote, event.URL)
+	}
+	if event.Branch() != "release/1.0" {
+		t.Errorf("Branch should be the remote branch 'release/1.0', was: %s", event.Branch())
+	}
+}
+
+func TestParsePrePushMostNotableKind(t *testing.T) {
+	mock := &mockIsAncestor{Ancestor: true}
+	mock.Mock()
+	defer mock.Restore()
+	input := strings.Join([]string{
+		"refs/heads/main " + shaB + " refs/heads/main " + shaA,
+		"refs/tags/v1.0 " + shaB + " refs/tags/v1.0 " + ZeroSha,
+		"",
+	}, "\n")
+	event, err := ParsePrePush("origin", "url", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error parsing pre-push input: %s", err.Error())
+	}
+	if event.Kind() != TagPush {
+		t.Errorf("A push with a branch update and a tag should be a tag push, was %s", event.Kind().Name())
+	}
+}
+
+func TestEventCommit(t *testing.T) {
+	event := Event{Updates: []RefUpdate{
+		{LocalRef: "(delete)", LocalSha: ZeroSha, RemoteRef: "refs/heads/old", RemoteSha: shaA},
+		{LocalRef: "refs/heads/main", LocalSha: shaB, RemoteRef: "refs/heads/main", RemoteSha: shaA},
+	}}
+	if event.Commit() != shaB {
+		t.Errorf("The commit should be the first one pushed, skipping deletes, was '%s'", event.Commit())
+	}
+	if (Event{Updates: event.Updates[:1]}).Commit() != "" {
+		t.Errorf("A push that only deletes should not have a commit")
+	}
+}
+
+func TestParsePrePushEmpty(t *testing.T) {
+	event, err := ParsePrePush("origin", "url", strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Error parsing empty pre-push input: %s", err.Error())
+	}
+	if event.Kind() != NoUpdates {
+		t.Errorf("An empty push should have no updates, was %s", event.Kind().Name())
+	}
+}
+
+func TestParsePrePushInvalid(t *testing.T) {
+	_, err := ParsePrePush("origin", "url", strings.NewReader("refs/heads/main "+shaB+"\n"))
+	if err == nil {
+		t.Errorf("Parsing an invalid pre-push line should return an error")
+	}
+}
+
+func TestParseEventKind(t *testing.T) {
+	for _, kind := range EventKinds() {
+		parsed, err := ParseEventKind(kind.Name())
+		if err != nil || parsed != kind {
+			t.Errorf("Parsing event kind '%s' should return itself, was %s (err: %v)", kind.Name(), parsed.Name(), err)
+		}
+	}
+	if _, err := ParseEventKind("bogus"); err == nil {
+		t.Errorf("Parsing an unknown event kind should return an error")
+	}
+}
+
+func TestIsAncestor(t *testing.T) {
+	repo, err := os.MkdirTemp("", "temp-repo-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary repository: %s", err.Error())
+	}
+	defer os.RemoveAll(repo)
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(repo)
+
+	commit := func(message string) string {
+		if _, err := git.Run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", message); err != nil {
+			t.Fatalf("Unable to commit: %s", err.Error())
+		}
+		sha, _ := git.Run("rev-parse", "HEAD")
+		return sha
+	}
+	if _, err := git.Run("init", "-q"); err != nil {
+		t.Fatalf("Unable to initialize repository: %s", err.Error())
+	}
+	first := commit("first")
+	second := commit("second")
+	git.Run("reset", "-q", "--hard", first)
+	rewritten := commit("rewritten")
+
+	if ancestor, err := IsAncestor(first, second); err != nil || !ancestor {
+		t.Errorf("First commit should be an ancestor of the second, ancestor: %t, err: %v", ancestor, err)
+	}
+	if ancestor, err := IsAncestor(second, rewritten); err != nil || ancestor {
+		t.Errorf("Second commit should not be an ancestor of a rewritten history, ancestor: %t, err: %v", ancestor, err)
+	}
+	if _, err := IsAncestor(shaA, second); err == nil {
+		t.Errorf("An unknown commit should return an error")
+	}
+}
@@ -0,0 +1,104 @@
+package push
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/git"
+)
+
+const (
+	pendingFile = "push-sounds-pending.json"
+)
+
+var (
+	GetCommonDir = git.CommonDir
+	Now          = time.Now
+)
+
+type pendingPush struct {
+	Event Event     `json:"event"`
+	Time  time.Time `json:"time"`
+}
+
+func pendingPath() (string, error) {
+	commonDir, err := GetCommonDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find git directory: %s", err.Error())
+	}
+	return filepath.Join(commonDir, pendingFile), nil
+}
+
+// SavePending records a push that is about to happen, so a sound can be played for it once git reports the
+// push succeeded.
+func SavePending(event Event) error {
+	path, err := pendingPath()
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(pendingPush{Event: event, Time: Now()})
+	if err != nil {
+		return fmt.Errorf("unable to save pending push: %s", err.Error())
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("unable to save pending push: %s", err.Error())
+	}
+	return nil
+}
+
+// HasPending checks there is a pending push for TakePending to claim.
+func HasPending() (bool, error) {
+	path, err := pendingPath()
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to read pending push: %s", err.Error())
+	}
+	return true, nil
+}
+
+// TakePending claims the pending push if it was saved within maxAge and the changes move the remote-tracking ref
+// of a pushed branch to the pushed commit.  The pending push is dropped when they don't.  A pending push can only
+// be taken once, even when several hooks try to take it at the same time.
+func TakePending(maxAge time.Duration, changes []RefChange) (Event, bool, error) {
+	path, err := pendingPath()
+	if err != nil {
+		return Event{}, false, err
+	}
+	// renaming is atomic, so only one process ends up with the pending push
+	claimed := path + "." + strconv.Itoa(os.Getpid())
+	if err := os.Rename(path, claimed); err != nil {
+		if os.IsNotExist(err) {
+			return Event{}, false, nil
+		}
+		return Event{}, false, fmt.Errorf("unable to claim pending push: %s", err.Error())
+	}
+	content, err := os.ReadFile(claimed)
+	if err != nil {
+		os.Remove(claimed)
+		return Event{}, false, fmt.Errorf("unable to read pending push: %s", err.Error())
+	}
+	pending := pendingPush{}
+	if err := json.Unmarshal(content, &pending); err != nil {
+		os.Remove(claimed)
+		return Event{}, false, fmt.Errorf("unable to read pending push: %s", err.Error())
+	}
+	if Now().Sub(pending.Time) > maxAge {
+		os.Remove(claimed)
+		return Event{}, false, nil
+	}
+	// a push that didn't move its remote-tracking refs failed, and a later fetch mustn't be mistaken for it
+	os.Remove(claimed)
+	if !PushedBranchMoved(changes, pending.Event) {
+		return Event{}, false, nil
+	}
+	return pending.Event, true, nil
+}
@@ -0,0 +1,89 @@
+package push
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func setupPending(t *testing.T) func() {
+	dir, err := os.MkdirTemp("", "temp-git-dir-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	origCommonDir := GetCommonDir
+	origNow := Now
+	GetCommonDir = func() (string, error) {
+		return dir, nil
+	}
+	return func() {
+		GetCommonDir = origCommonDir
+		Now = origNow
+		os.RemoveAll(dir)
+	}
+}
+
+func TestTakePending(t *testing.T) {
+	defer setupPending(t)()
+	pushed := Event{Remote: "origin", URL: "url", Updates: []RefUpdate{
+		{LocalRef: "refs/heads/main", LocalSha: shaB, RemoteRef: "refs/heads/main", RemoteSha: shaA},
+	}}
+	originMoved := []RefChange{{OldSha: shaA, NewSha: shaB, Ref: "refs/remotes/origin/main"}}
+
+	if _, found, err := TakePending(time.Minute, originMoved); err != nil || found {
+		t.Fatalf("There should be no pending push before one is saved, found: %t, err: %v", found, err)
+	}
+	if err := SavePending(pushed); err != nil {
+		t.Fatalf("Error saving pending push: %s", err.Error())
+	}
+	event, found, err := TakePending(time.Minute, originMoved)
+	if err != nil || !found {
+		t.Fatalf("The pending push should have been taken, found: %t, err: %v", found, err)
+	}
+	if event.Remote != "origin" || event.URL != "url" {
+		t.Errorf("The pending push should be the saved push, was: %#v", event)
+	}
+	if _, found, _ := TakePending(time.Minute, originMoved); found {
+		t.Errorf("A pending push should only be taken once")
+	}
+}
+
+func TestTakePendingDropsOtherChanges(t *testing.T) {
+	pushed := Event{Remote: "origin", URL: "url", Updates: []RefUpdate{
+		{LocalRef: "refs/heads/main", LocalSha: shaB, RemoteRef: "refs/heads/main", RemoteSha: shaA},
+	}}
+	cases := map[string][]RefChange{
+		"another remote": {{OldSha: shaA, NewSha: shaB, Ref: "refs/remotes/upstream/main"}},
+		"another branch": {{OldSha: shaA, NewSha: shaB, Ref: "refs/remotes/origin/feature"}},
+		"another commit": {{OldSha: shaB, NewSha: shaA, Ref: "refs/remotes/origin/main"}},
+		"a local commit": {{OldSha: shaA, NewSha: shaB, Ref: "refs/heads/main"}},
+	}
+	for name, changes := range cases {
+		cleanup := setupPending(t)
+		SavePending(pushed)
+		if _, found, _ := TakePending(time.Minute, changes); found {
+			t.Errorf("The pending push should not be taken by %s", name)
+		}
+		// a rejected push is dropped, so a fetch that follows doesn't play for it
+		fetched := []RefChange{{OldSha: shaA, NewSha: shaB, Ref: "refs/remotes/origin/main"}}
+		if _, found, _ := TakePending(time.Minute, fetched); found {
+			t.Errorf("The pending push should have been dropped by %s", name)
+		}
+		cleanup()
+	}
+}
+
+func TestTakePendingExpired(t *testing.T) {
+	defer setupPending(t)()
+	Now = func() time.Time {
+		return time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	}
+	SavePending(Event{Remote: "origin"})
+	Now = func() time.Time {
+		return time.Date(2021, 1, 1, 13, 0, 0, 0, time.UTC)
+	}
+	originMoved := []RefChange{{OldSha: shaA, NewSha: shaB, Ref: "refs/remotes/origin/main"}}
+	if _, found, _ := TakePending(time.Minute, originMoved); found {
+		t.Errorf("A pending push older than the max age should not be taken")
+	}
+}
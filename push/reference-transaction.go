@@ -0,0 +1,90 @@
+package push
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// Committed is the reference-transaction hook state once ref updates have been written.
+	Committed = "committed"
+
+	remoteTrackingPrefix = "refs/remotes/"
+)
+
+// RefChange is one line of the input git gives the reference-transaction hook.
+type RefChange struct {
+	OldSha string
+	NewSha string
+	Ref    string
+}
+
+// ParseReferenceTransaction reads the "<old sha> <new sha> <ref>" lines git gives the reference-transaction hook.
+func ParseReferenceTransaction(input io.Reader) ([]RefChange, error) {
+	changes := []RefChange{}
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return changes, fmt.Errorf("invalid reference-transaction line: %s", line)
+		}
+		changes = append(changes, RefChange{OldSha: fields[0], NewSha: fields[1], Ref: fields[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return changes, fmt.Errorf("unable to read reference-transaction input: %s", err.Error())
+	}
+	return changes, nil
+}
+
+// RemoteTrackingMoved reports whether any remote-tracking ref for remote changed.  Any remote matches when remote
+// is empty.
+func RemoteTrackingMoved(changes []RefChange, remote string) bool {
+	prefix := remoteTrackingPrefix
+	if remote != "" {
+		prefix += remote + "/"
+	}
+	for _, change := range changes {
+		if strings.HasPrefix(change.Ref, prefix) && change.OldSha != change.NewSha {
+			return true
+		}
+	}
+	return false
+}
+
+// TracksPush reports whether git moves remote-tracking refs once the push succeeds, which it only does for branches
+// pushed to a named remote.  Pushes of tags alone, and pushes to a URL, can't be told apart from a failed push.
+func TracksPush(event Event) bool {
+	if event.Remote == "" || event.Remote == event.URL {
+		return false
+	}
+	for _, update := range event.Updates {
+		if update.Branch() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PushedBranchMoved reports whether the changes move the remote-tracking ref of a branch in the push to the commit
+// that was pushed.
+func PushedBranchMoved(changes []RefChange, event Event) bool {
+	for _, update := range event.Updates {
+		branch := update.Branch()
+		if branch == "" {
+			continue
+		}
+		ref := remoteTrackingPrefix + event.Remote + "/" + branch
+		for _, change := range changes {
+			if change.Ref == ref && change.NewSha == update.LocalSha && change.OldSha != change.NewSha {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package push
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReferenceTransaction(t *testing.T) {
+	input := shaA + " " + shaB + " refs/remotes/origin/main\n" + ZeroSha + " " + shaA + " refs/heads/feature\n"
+	changes, err := ParseReferenceTransaction(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error parsing reference-transaction input: %s", err.Error())
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected two changes, got: %#v", changes)
+	}
+	if changes[0].OldSha != shaA || changes[0].NewSha != shaB || changes[0].Ref != "refs/remotes/origin/main" {
+		t.Errorf("First change was parsed incorrectly: %#v", changes[0])
+	}
+	if _, err := ParseReferenceTransaction(strings.NewReader("not valid\n")); err == nil {
+		t.Errorf("Parsing an invalid reference-transaction line should return an error")
+	}
+}
+
+func TestRemoteTrackingMoved(t *testing.T) {
+	changes := []RefChange{
+		{OldSha: shaA, NewSha: shaB, Ref: "refs/heads/main"},
+		{OldSha: shaA, NewSha: shaA, Ref: "refs/remotes/upstream/main"},
+		{OldSha: shaA, NewSha: shaB, Ref: "refs/remotes/origin/main"},
+	}
+	if !RemoteTrackingMoved(changes, "") {
+		t.Errorf("A moved remote-tracking ref should match any remote")
+	}
+	if !RemoteTrackingMoved(changes, "origin") {
+		t.Errorf("A moved remote-tracking ref for origin should match origin")
+	}
+	if RemoteTrackingMoved(changes, "upstream") {
+		t.Errorf("A remote-tracking ref that didn't change should not match")
+	}
+	if RemoteTrackingMoved(changes[:1], "") {
+		t.Errorf("A local branch update should not match")
+	}
+}
+
+func TestTracksPush(t *testing.T) {
+	branch := RefUpdate{LocalSha: shaB, RemoteRef: "refs/heads/main"}
+	tag := RefUpdate{LocalSha: shaB, RemoteRef: "refs/tags/v1.0"}
+	cases := []struct {
+		Event    Event
+		Expected bool
+	}{
+		{Event{Remote: "origin", URL: "git@example.com:repo.git", Updates: []RefUpdate{branch}}, true},
+		{Event{Remote: "origin", URL: "git@example.com:repo.git", Updates: []RefUpdate{tag, branch}}, true},
+		{Event{Remote: "origin", URL: "git@example.com:repo.git", Updates: []RefUpdate{tag}}, false},
+		{Event{Remote: "git@example.com:repo.git", URL: "git@example.com:repo.git", Updates: []RefUpdate{branch}}, false},
+	}
+	for _, c := range cases {
+		if TracksPush(c.Event) != c.Expected {
+			t.Errorf("Expected %#v to be tracked %t", c.Event, c.Expected)
+		}
+	}
+}
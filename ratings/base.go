@@ -0,0 +1,156 @@
+package ratings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jasoncorbett/push-sounds/selection"
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+const (
+	stateFile = "ratings.json"
+
+	// a file's weight in its library is normalWeight, one more when rated up and one less when rated down, and
+	// favoriteFactor times that for a favorite
+	normalWeight   = 2
+	favoriteFactor = 3
+)
+
+// Rating is what the user thinks of a sound.
+type Rating struct {
+	// Score counts the times the sound was rated up, less the times it was rated down.
+	Score    int  `json:"score,omitempty"`
+	Banned   bool `json:"banned,omitempty"`
+	Favorite bool `json:"favorite,omitempty"`
+}
+
+// Weight is how likely the sound is to play compared to unrated sounds in its library, which have a weight of 2.
+func (r Rating) Weight() int {
+	weight := normalWeight
+	switch {
+	case r.Score > 0:
+		weight++
+	case r.Score < 0:
+		weight--
+	}
+	if r.Favorite {
+		weight *= favoriteFactor
+	}
+	return weight
+}
+
+// Marks describes the rating for listing next to the sound, it is empty for an unrated sound.
+func (r Rating) Marks() string {
+	marks := ""
+	if r.Banned {
+		marks += " [banned]"
+	}
+	if r.Favorite {
+		marks += " [favorite]"
+	}
+	if r.Score != 0 {
+		marks += fmt.Sprintf(" [%+d]", r.Score)
+	}
+	return marks
+}
+
+// Ratings are the ratings of every rated sound, by the absolute path of the sound.
+type Ratings map[string]Rating
+
+func key(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return filepath.Clean(file)
+}
+
+func statePath() (string, error) {
+	stateDir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, stateFile), nil
+}
+
+// Load reads the ratings, a missing ratings file has no ratings.
+func Load() (Ratings, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	ratings := Ratings{}
+	if err := state.Load(path, &ratings); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// For is the rating of a sound.
+func (r Ratings) For(file string) Rating {
+	return r[key(file)]
+}
+
+// Files lists the rated sounds.
+func (r Ratings) Files() []string {
+	files := []string{}
+	for file := range r {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Apply leaves out the banned candidates and gives the rest the file weight of their rating.
+func (r Ratings) Apply(candidates []selection.Candidate) []selection.Candidate {
+	result := []selection.Candidate{}
+	for _, candidate := range candidates {
+		rating := r.For(candidate.File)
+		if rating.Banned {
+			continue
+		}
+		candidate.FileWeight = rating.Weight()
+		result = append(result, candidate)
+	}
+	return result
+}
+
+// Update changes the rating of a sound.  Two pushes at the same time never lose each other's changes.
+func Update(file string, change func(*Rating)) (Rating, error) {
+	path, err := statePath()
+	if err != nil {
+		return Rating{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Rating{}, fmt.Errorf("unable to create the ratings directory: %s", err.Error())
+	}
+	unlock, err := state.Lock(path)
+	if err != nil {
+		return Rating{}, err
+	}
+	defer unlock()
+	ratings, err := Load()
+	if err != nil {
+		return Rating{}, err
+	}
+	rating := ratings.For(file)
+	change(&rating)
+	if rating == (Rating{}) {
+		delete(ratings, key(file))
+	} else {
+		ratings[key(file)] = rating
+	}
+	return rating, state.Save(path, ratings)
+}
+
+// Resolve finds the sound a user named, either by its path or as library/file under the library base.
+func Resolve(libraryBase string, name string) (string, error) {
+	for _, path := range []string{name, filepath.Join(libraryBase, name)} {
+		if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+			return key(path), nil
+		}
+	}
+	return "", fmt.Errorf("no sound %s, give a path or library/file in %s", name, libraryBase)
+}
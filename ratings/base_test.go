@@ -0,0 +1,78 @@
+package ratings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/selection"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+)
+
+func TestRatingWeight(t *testing.T) {
+	cases := []struct {
+		Rating   Rating
+		Expected int
+	}{
+		{Rating{}, 2},
+		{Rating{Score: 3}, 3},
+		{Rating{Score: -1}, 1},
+		{Rating{Favorite: true}, 6},
+		{Rating{Favorite: true, Score: 1}, 9},
+	}
+	for _, c := range cases {
+		if actual := c.Rating.Weight(); actual != c.Expected {
+			t.Errorf("%+v should have a weight of %d, was %d", c.Rating, c.Expected, actual)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	ratings := Ratings{
+		key("/base/default/banned"):   {Banned: true},
+		key("/base/default/favorite"): {Favorite: true},
+	}
+	candidates := ratings.Apply([]selection.Candidate{
+		{File: "/base/default/banned", Library: "default", Weight: 1},
+		{File: "/base/default/favorite", Library: "default", Weight: 1},
+		{File: "/base/default/plain", Library: "default", Weight: 1},
+	})
+	if len(candidates) != 2 || candidates[0].FileWeight != 6 || candidates[1].FileWeight != 2 {
+		t.Errorf("Banned files should be left out and the rest weighted by rating, was %+v", candidates)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	statetest.UseTemp(t)
+	Update("/base/default/a", func(r *Rating) { r.Score++ })
+	Update("/base/default/a", func(r *Rating) { r.Score++ })
+	Update("/base/default/b", func(r *Rating) { r.Banned = true })
+	ratings, err := Load()
+	if err != nil {
+		t.Fatalf("Unable to load ratings: %s", err.Error())
+	}
+	if ratings.For("/base/default/a").Score != 2 || !ratings.For("/base/default/b").Banned {
+		t.Errorf("Ratings should be saved, were %+v", ratings)
+	}
+	Update("/base/default/b", func(r *Rating) { r.Banned = false })
+	if ratings, _ := Load(); len(ratings.Files()) != 1 {
+		t.Errorf("A sound without a rating should be forgotten, ratings were %+v", ratings)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	base := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "default"), 0755)
+	os.WriteFile(filepath.Join(base, "default", "beep.mp3"), []byte{}, 0644)
+	expected := filepath.Join(base, "default", "beep.mp3")
+	for _, name := range []string{"default/beep.mp3", expected} {
+		if actual, err := Resolve(base, name); err != nil || actual != expected {
+			t.Errorf("%s should resolve to %s, was %s (err: %v)", name, expected, actual, err)
+		}
+	}
+	for _, name := range []string{"default/missing.mp3", "default"} {
+		if _, err := Resolve(base, name); err == nil {
+			t.Errorf("%s should not resolve to a sound", name)
+		}
+	}
+}
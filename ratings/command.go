@@ -0,0 +1,111 @@
+package ratings
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/selection"
+	"github.com/urfave/cli/v2"
+)
+
+var RateCommand = &cli.Command{
+	Name:      "rate",
+	Usage:     "Rate a sound up or down, making it more or less likely to play with the random, weighted and not-recently-played strategies",
+	ArgsUsage: "<library/file> up|down",
+	Action:    rateSound,
+}
+
+var BanCommand = &cli.Command{
+	Name:      "ban",
+	Usage:     "Never play a sound",
+	ArgsUsage: "<library/file>",
+	Action:    changeSound("banned", func(r *Rating) { r.Banned = true }, false),
+}
+
+var UnbanCommand = &cli.Command{
+	Name:      "unban",
+	Usage:     "Let a banned sound play again",
+	ArgsUsage: "<library/file>",
+	Action:    changeSound("unbanned", func(r *Rating) { r.Banned = false }, false),
+}
+
+var FavoriteCommand = &cli.Command{
+	Name:      "favorite",
+	Usage:     "Play a sound more often with the random, weighted and not-recently-played strategies",
+	ArgsUsage: "<library/file>",
+	Action:    changeSound("added to favorites", func(r *Rating) { r.Favorite = true }, true),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "remove",
+			Usage: "stop it being a favorite",
+		},
+	},
+}
+
+// soundArg resolves the sound named by the first argument, with the settings it was resolved with.
+func soundArg(c *cli.Context) (string, *config.Settings, error) {
+	if c.Args().Len() == 0 {
+		return "", nil, fmt.Errorf("required sound to rate, as library/file or a path")
+	}
+	settings, err := config.LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	file, err := Resolve(settings.String(c, "library-base"), c.Args().First())
+	return file, settings, err
+}
+
+// warnUnweighted says when the strategy in use ignores the ratings and favorites being changed.
+func warnUnweighted(c *cli.Context, settings *config.Settings) {
+	strategy := settings.Lookup(c, "strategy")
+	if !selection.UsesFileWeights(strategy.String()) {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: the %s strategy from %s ignores ratings and favorites, only random, weighted and not-recently-played use them\n", strategy.String(), strategy.Source.Name())
+	}
+}
+
+func rateSound(c *cli.Context) error {
+	step := 0
+	switch c.Args().Get(1) {
+	case "up":
+		step = 1
+	case "down":
+		step = -1
+	default:
+		return fmt.Errorf("rate a sound up or down, was '%s'", c.Args().Get(1))
+	}
+	file, settings, err := soundArg(c)
+	if err != nil {
+		return err
+	}
+	rating, err := Update(file, func(r *Rating) { r.Score += step })
+	if err != nil {
+		return err
+	}
+	warnUnweighted(c, settings)
+	fmt.Fprintf(c.App.Writer, "Rated %s %s, its score is now %+d\n", filepath.Base(file), c.Args().Get(1), rating.Score)
+	return nil
+}
+
+// changeSound is the action for a command that changes the rating of the sound in its argument.  weighted is true
+// when the change only matters to the strategies that use file weights.
+func changeSound(done string, change func(*Rating), weighted bool) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		file, settings, err := soundArg(c)
+		if err != nil {
+			return err
+		}
+		message, update := done, change
+		if c.Bool("remove") {
+			message, update = "removed from favorites", func(r *Rating) { r.Favorite = false }
+		}
+		if _, err := Update(file, update); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.App.Writer, "%s %s\n", filepath.Base(file), message)
+		if weighted && !c.Bool("remove") {
+			warnUnweighted(c, settings)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+package ratings
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+func TestFavoriteWarnsUnweightedStrategy(t *testing.T) {
+	statetest.UseTemp(t)
+	origGitConfig := config.GetGitConfig
+	defer func() {
+		config.GetGitConfig = origGitConfig
+	}()
+	config.GetGitConfig = func() (*config.Config, error) {
+		return &config.Config{}, nil
+	}
+	base := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "default"), 0755)
+	os.WriteFile(filepath.Join(base, "default", "beep.mp3"), []byte{}, 0644)
+
+	for strategy, warns := range map[string]bool{"weighted": false, "random": false, "round-robin": true, "hash": true} {
+		os.Setenv("PUSH_SOUNDS_STRATEGY", strategy)
+		var errors bytes.Buffer
+		app := &cli.App{
+			Flags: []cli.Flag{
+				&cli.PathFlag{Name: "library-base", Value: base},
+				&cli.PathFlag{Name: "config", Value: filepath.Join(base, "missing.yaml")},
+			},
+			Commands:  []*cli.Command{FavoriteCommand},
+			Writer:    io.Discard,
+			ErrWriter: &errors,
+		}
+		if err := app.Run([]string{"push-sounds", "favorite", "default/beep.mp3"}); err != nil {
+			t.Fatalf("Favoriting a sound should not return an error: %s", err.Error())
+		}
+		if strings.Contains(errors.String(), "ignores ratings and favorites") != warns {
+			t.Errorf("With the %s strategy favoriting should warn %t, said: %s", strategy, warns, errors.String())
+		}
+	}
+	os.Unsetenv("PUSH_SOUNDS_STRATEGY")
+}
@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Patterns is a list of globs, written in config as either a single glob or a list of them.  A * matches
+// within one path segment, ** matches across segments.
+type Patterns []string
+
+func (p *Patterns) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*p = Patterns{value.Value}
+		return nil
+	}
+	var patterns []string
+	if err := value.Decode(&patterns); err != nil {
+		return err
+	}
+	*p = patterns
+	return nil
+}
+
+// Matches is true when there are no patterns, or when the value matches one of them.
+func (p Patterns) Matches(value string) bool {
+	if len(p) == 0 {
+		return true
+	}
+	for _, pattern := range p {
+		if globToRegexp(pattern).MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func globToRegexp(glob string) *regexp.Regexp {
+	var expression strings.Builder
+	expression.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expression.WriteString("[^/]*")
+		case glob[i] == '?':
+			expression.WriteString("[^/]")
+		default:
+			expression.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String())
+}
+
+// Target is what a push is matched against.
+type Target struct {
+	Branch string
+	Remote string
+	URL    string
+	Repo   string
+	Event  string
+	// Time is when the push happens, in local time.
+	Time time.Time
+}
+
+// Rule chooses libraries, or no sound at all, for pushes matching all of its patterns.
+type Rule struct {
+	Name   string   `yaml:"name,omitempty"`
+	Branch Patterns `yaml:"branch,omitempty"`
+	Remote Patterns `yaml:"remote,omitempty"`
+	URL    Patterns `yaml:"url,omitempty"`
+	Repo   Patterns `yaml:"repo,omitempty"`
+	Event  Patterns `yaml:"event,omitempty"`
+	// Days, Dates and Times limit the rule to a schedule, like a spooky theme in late October.
+	Days      Days     `yaml:"days,omitempty"`
+	Dates     Dates    `yaml:"dates,omitempty"`
+	Times     Times    `yaml:"times,omitempty"`
+	Libraries []string `yaml:"libraries,omitempty"`
+	Mute      bool     `yaml:"mute,omitempty"`
+}
+
+func (r Rule) Matches(target Target) bool {
+	return r.Branch.Matches(target.Branch) &&
+		r.Remote.Matches(target.Remote) &&
+		r.URL.Matches(target.URL) &&
+		r.Repo.Matches(target.Repo) &&
+		r.Event.Matches(target.Event) &&
+		r.Days.Matches(target.Time) &&
+		r.Dates.Matches(target.Time) &&
+		r.Times.Matches(target.Time)
+}
+
+// Describe names the rule for people, using its name when it has one.
+func (r Rule) Describe(index int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("rule %d (%s)", index+1, r.Name)
+	}
+	return fmt.Sprintf("rule %d", index+1)
+}
+
+func (r Rule) Validate() error {
+	if !r.Mute && len(r.Libraries) == 0 {
+		return fmt.Errorf("a rule needs either libraries or mute")
+	}
+	for _, schedule := range []interface{ Validate() error }{r.Days, r.Dates, r.Times} {
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match returns the index of the first rule matching target, or -1 if none of them do.
+func Match(rules []Rule, target Target) int {
+	for i, rule := range rules {
+		if rule.Matches(target) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPatternsMatches(t *testing.T) {
+	cases := []struct {
+		Patterns Patterns
+		Value    string
+		Matches  bool
+	}{
+		{Patterns{}, "anything", true},
+		{Patterns{"main"}, "main", true},
+		{Patterns{"main"}, "maint", false},
+		{Patterns{"main", "release/*"}, "release/1.0", true},
+		{Patterns{"release/*"}, "release/1.0/hotfix", false},
+		{Patterns{"release/**"}, "release/1.0/hotfix", true},
+		{Patterns{"feature-?"}, "feature-a", true},
+		{Patterns{"**github.com**"}, "https://github.com/jasoncorbett/push-sounds", true},
+		{Patterns{"v1.0"}, "v1x0", false},
+		{Patterns{"main"}, "", false},
+	}
+	for _, c := range cases {
+		if c.Patterns.Matches(c.Value) != c.Matches {
+			t.Errorf("Expected %#v matching '%s' to be %t", c.Patterns, c.Value, c.Matches)
+		}
+	}
+}
+
+func TestPatternsUnmarshal(t *testing.T) {
+	rule := Rule{}
+	err := yaml.Unmarshal([]byte("branch: main\nremote: [origin, upstream]\nlibraries: [fanfare]\n"), &rule)
+	if err != nil {
+		t.Fatalf("Error unmarshalling rule: %s", err.Error())
+	}
+	if len(rule.Branch) != 1 || rule.Branch[0] != "main" {
+		t.Errorf("A single branch pattern should be a list of one, was: %#v", rule.Branch)
+	}
+	if len(rule.Remote) != 2 || rule.Remote[1] != "upstream" {
+		t.Errorf("A list of remote patterns should be kept as a list, was: %#v", rule.Remote)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []Rule{
+		{Name: "mirror", Remote: Patterns{"mirror"}, Mute: true},
+		{Name: "release", Branch: Patterns{"main", "release/*"}, Libraries: []string{"fanfare"}},
+		{Name: "work", Repo: Patterns{"/home/*/work/**"}, URL: Patterns{"**github.com**"}, Libraries: []string{"quiet"}},
+	}
+	cases := []struct {
+		Target   Target
+		Expected int
+	}{
+		{Target{Branch: "main", Remote: "mirror"}, 0},
+		{Target{Branch: "release/2", Remote: "origin"}, 1},
+		{Target{Branch: "feature", Remote: "origin", Repo: "/home/me/work/project", URL: "git@github.com:me/project.git"}, 2},
+		{Target{Branch: "feature", Remote: "origin", Repo: "/home/me/work/project", URL: "git@gitlab.com:me/project.git"}, -1},
+	}
+	for _, c := range cases {
+		if actual := Match(rules, c.Target); actual != c.Expected {
+			t.Errorf("Expected %#v to match rule %d, matched %d", c.Target, c.Expected, actual)
+		}
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	if err := (Rule{Branch: Patterns{"main"}}).Validate(); err == nil {
+		t.Errorf("A rule without libraries or mute should not be valid")
+	}
+	if err := (Rule{Mute: true}).Validate(); err != nil {
+		t.Errorf("A mute rule should be valid: %s", err.Error())
+	}
+}
+
+func TestRuleDescribe(t *testing.T) {
+	if description := (Rule{Name: "mirror"}).Describe(0); description != "rule 1 (mirror)" {
+		t.Errorf("Expected named rule description 'rule 1 (mirror)', was '%s'", description)
+	}
+	if description := (Rule{}).Describe(2); description != "rule 3" {
+		t.Errorf("Expected unnamed rule description 'rule 3', was '%s'", description)
+	}
+}
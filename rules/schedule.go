@@ -0,0 +1,214 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	rangeSeparator = ".."
+	dateFormat     = "01-02"
+	timeFormat     = "15:04"
+)
+
+// unmarshalList reads either a single value or a list of them.
+func unmarshalList(value *yaml.Node) ([]string, error) {
+	var list Patterns
+	err := list.UnmarshalYAML(value)
+	return list, err
+}
+
+// Days are days of the week, like mon or saturday, or weekdays and weekends.
+type Days []string
+
+func (d *Days) UnmarshalYAML(value *yaml.Node) error {
+	list, err := unmarshalList(value)
+	*d = list
+	return err
+}
+
+// weekdays finds the days of the week a day means.
+func weekdays(day string) ([]time.Weekday, error) {
+	day = strings.ToLower(day)
+	switch day {
+	case "weekdays":
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, nil
+	case "weekends":
+		return []time.Weekday{time.Saturday, time.Sunday}, nil
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if day == name || day == name[:3] {
+			return []time.Weekday{weekday}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown day '%s', expected a day like mon or monday, weekdays or weekends", day)
+}
+
+// Matches is true when there are no days, or when t is on one of them.
+func (d Days) Matches(t time.Time) bool {
+	if len(d) == 0 {
+		return true
+	}
+	for _, day := range d {
+		days, _ := weekdays(day)
+		for _, weekday := range days {
+			if t.Weekday() == weekday {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d Days) Validate() error {
+	for _, day := range d {
+		if _, err := weekdays(day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitRange splits a range like start..end into its ends, either of which can be left out.
+func splitRange(value string, kind string, example string) (string, string, error) {
+	parts := strings.Split(value, rangeSeparator)
+	switch len(parts) {
+	case 1:
+		return parts[0], parts[0], nil
+	case 2:
+		return parts[0], parts[1], nil
+	default:
+		return "", "", fmt.Errorf("invalid %s range '%s', expected something like %s", kind, value, example)
+	}
+}
+
+// Dates are days of the year like 12-25, or ranges of them like 10-20..10-31 including both ends.  A range can
+// wrap past the new year, and 12-01.. runs to the end of the year.
+type Dates []string
+
+func (d *Dates) UnmarshalYAML(value *yaml.Node) error {
+	list, err := unmarshalList(value)
+	*d = list
+	return err
+}
+
+// dateRange reads a range of dates as the months and days they start and end on, packed as month*100+day.
+func dateRange(value string) (int, int, error) {
+	start, end, err := splitRange(value, "date", "10-20..10-31")
+	if err != nil {
+		return 0, 0, err
+	}
+	ends := []int{101, 1231}
+	for i, part := range []string{start, end} {
+		if part == "" {
+			continue
+		}
+		date, err := time.Parse(dateFormat, part)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid date '%s' in '%s', expected month-day like 12-25", part, value)
+		}
+		ends[i] = dayOfYear(date)
+	}
+	return ends[0], ends[1], nil
+}
+
+func dayOfYear(t time.Time) int {
+	return int(t.Month())*100 + t.Day()
+}
+
+// inRange is true when value is from start to end, wrapping around when end is before start.
+func inRange(value int, start int, end int, inclusive bool) bool {
+	afterStart := value >= start
+	beforeEnd := value < end || (inclusive && value == end)
+	if start <= end {
+		return afterStart && beforeEnd
+	}
+	return afterStart || beforeEnd
+}
+
+// Matches is true when there are no dates, or when t is in one of them.
+func (d Dates) Matches(t time.Time) bool {
+	if len(d) == 0 {
+		return true
+	}
+	for _, value := range d {
+		start, end, err := dateRange(value)
+		if err == nil && inRange(dayOfYear(t), start, end, true) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d Dates) Validate() error {
+	for _, value := range d {
+		if _, _, err := dateRange(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Times are ranges of the time of day like 18:00..22:00, from the start up to but not including the end.  A
+// range can wrap past midnight like 22:00..06:00, and 18:00.. runs to midnight.
+type Times []string
+
+func (ts *Times) UnmarshalYAML(value *yaml.Node) error {
+	list, err := unmarshalList(value)
+	*ts = list
+	return err
+}
+
+// timeRange reads a range of times as the minutes after midnight they start and end on.
+func timeRange(value string) (int, int, error) {
+	start, end, err := splitRange(value, "time", "18:00..22:00")
+	if err != nil {
+		return 0, 0, err
+	}
+	if !strings.Contains(value, rangeSeparator) {
+		return 0, 0, fmt.Errorf("invalid time range '%s', expected something like 18:00..22:00", value)
+	}
+	ends := []int{0, 24 * 60}
+	for i, part := range []string{start, end} {
+		if part == "" || part == "24:00" {
+			continue
+		}
+		parsed, err := time.Parse(timeFormat, part)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid time '%s' in '%s', expected hours:minutes like 18:00", part, value)
+		}
+		ends[i] = minuteOfDay(parsed)
+	}
+	return ends[0], ends[1], nil
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// Matches is true when there are no times, or when t is in one of them.
+func (ts Times) Matches(t time.Time) bool {
+	if len(ts) == 0 {
+		return true
+	}
+	for _, value := range ts {
+		start, end, err := timeRange(value)
+		if err == nil && inRange(minuteOfDay(t), start, end, false) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts Times) Validate() error {
+	for _, value := range ts {
+		if _, _, err := timeRange(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// at is a time in 2024, when October 31st is a Thursday.
+func at(month time.Month, day int, hour int, minute int) time.Time {
+	return time.Date(2024, month, day, hour, minute, 0, 0, time.Local)
+}
+
+func TestDaysMatches(t *testing.T) {
+	cases := []struct {
+		Days    Days
+		Time    time.Time
+		Matches bool
+	}{
+		{Days{}, at(10, 31, 12, 0), true},
+		{Days{"thu"}, at(10, 31, 12, 0), true},
+		{Days{"Thursday"}, at(10, 31, 12, 0), true},
+		{Days{"fri", "sat"}, at(10, 31, 12, 0), false},
+		{Days{"weekdays"}, at(10, 31, 12, 0), true},
+		{Days{"weekends"}, at(11, 2, 12, 0), true},
+		{Days{"weekends"}, at(11, 4, 12, 0), false},
+	}
+	for _, c := range cases {
+		if c.Days.Matches(c.Time) != c.Matches {
+			t.Errorf("Expected %v matching %s to be %t", c.Days, c.Time.Format(time.RFC1123), c.Matches)
+		}
+	}
+}
+
+func TestDatesMatches(t *testing.T) {
+	cases := []struct {
+		Dates   Dates
+		Time    time.Time
+		Matches bool
+	}{
+		{Dates{}, at(10, 31, 12, 0), true},
+		{Dates{"10-20..10-31"}, at(10, 31, 23, 59), true},
+		{Dates{"10-20..10-31"}, at(11, 1, 0, 0), false},
+		{Dates{"10-20..10-31"}, at(10, 19, 12, 0), false},
+		{Dates{"12-25"}, at(12, 25, 8, 0), true},
+		{Dates{"12-25"}, at(12, 26, 8, 0), false},
+		{Dates{"12-01.."}, at(12, 31, 8, 0), true},
+		{Dates{"12-20..01-05"}, at(1, 2, 8, 0), true},
+		{Dates{"12-20..01-05"}, at(6, 2, 8, 0), false},
+		{Dates{"..01-05", "07-04"}, at(7, 4, 8, 0), true},
+	}
+	for _, c := range cases {
+		if c.Dates.Matches(c.Time) != c.Matches {
+			t.Errorf("Expected %v matching %s to be %t", c.Dates, c.Time.Format(time.RFC1123), c.Matches)
+		}
+	}
+}
+
+func TestTimesMatches(t *testing.T) {
+	cases := []struct {
+		Times   Times
+		Time    time.Time
+		Matches bool
+	}{
+		{Times{}, at(10, 31, 12, 0), true},
+		{Times{"18:00.."}, at(10, 31, 18, 0), true},
+		{Times{"18:00.."}, at(10, 31, 23, 59), true},
+		{Times{"18:00.."}, at(10, 31, 17, 59), false},
+		{Times{"09:00..17:00"}, at(10, 31, 17, 0), false},
+		{Times{"22:00..06:00"}, at(10, 31, 3, 0), true},
+		{Times{"22:00..06:00"}, at(10, 31, 12, 0), false},
+		{Times{"..08:00", "12:00..13:00"}, at(10, 31, 12, 30), true},
+	}
+	for _, c := range cases {
+		if c.Times.Matches(c.Time) != c.Matches {
+			t.Errorf("Expected %v matching %s to be %t", c.Times, c.Time.Format(time.RFC1123), c.Matches)
+		}
+	}
+}
+
+func TestScheduleValidate(t *testing.T) {
+	valid := Rule{Libraries: []string{"spooky"}, Days: Days{"weekends"}, Dates: Dates{"10-20..10-31"}, Times: Times{"18:00..24:00"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("A rule with a schedule should be valid: %s", err.Error())
+	}
+	invalid := []Rule{
+		{Libraries: []string{"a"}, Days: Days{"someday"}},
+		{Libraries: []string{"a"}, Dates: Dates{"10-32"}},
+		{Libraries: []string{"a"}, Dates: Dates{"10-01..10-05..10-09"}},
+		{Libraries: []string{"a"}, Times: Times{"18:00"}},
+		{Libraries: []string{"a"}, Times: Times{"6pm.."}},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", rule)
+		}
+	}
+}
+
+func TestScheduleUnmarshal(t *testing.T) {
+	rule := Rule{}
+	err := yaml.Unmarshal([]byte("days: weekends\ndates: [10-20..10-31, 12-25]\ntimes: 18:00..\nlibraries: [spooky]\n"), &rule)
+	if err != nil {
+		t.Fatalf("Error unmarshalling rule: %s", err.Error())
+	}
+	if len(rule.Days) != 1 || len(rule.Dates) != 2 || len(rule.Times) != 1 || rule.Times[0] != "18:00.." {
+		t.Errorf("A schedule should be read as single values or lists, was: %+v", rule)
+	}
+	if !rule.Matches(Target{Time: at(10, 26, 19, 0)}) || rule.Matches(Target{Time: at(10, 26, 17, 0)}) {
+		t.Errorf("A rule should only match pushes in its schedule")
+	}
+}
@@ -0,0 +1,94 @@
+package selection
+
+import (
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/history"
+)
+
+var (
+	Now = time.Now
+)
+
+// lastPlayed is when each file was last played, going by the history.
+func lastPlayed(entries []history.Entry) map[string]time.Time {
+	played := map[string]time.Time{}
+	for _, entry := range entries {
+		if entry.Time.After(played[entry.File]) {
+			played[entry.File] = entry.Time
+		}
+	}
+	return played
+}
+
+// leastRecentlyPlayed picks a file that has never been played, or else the one played longest ago.
+func leastRecentlyPlayed(candidates []Candidate, entries []history.Entry, random Random) string {
+	played := lastPlayed(entries)
+	oldest := []string{}
+	var oldestTime time.Time
+	for _, file := range files(candidates) {
+		switch {
+		case len(oldest) == 0 || played[file].Before(oldestTime):
+			oldest = []string{file}
+			oldestTime = played[file]
+		case played[file].Equal(oldestTime):
+			oldest = append(oldest, file)
+		}
+	}
+	return oldest[random.Intn(len(oldest))]
+}
+
+// leastRecentlyPlayedSelector plays the file that has gone longest without being played.
+type leastRecentlyPlayedSelector struct {
+	history []history.Entry
+	random  Random
+}
+
+func (s *leastRecentlyPlayedSelector) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	return leastRecentlyPlayed(candidates, s.history, s.random), nil
+}
+
+// notRecentlyPlayedSelector picks from the files that weren't played in the last few plays or hours the way the
+// weighted strategy would, when every file was played recently it plays the least recently played one.
+type notRecentlyPlayedSelector struct {
+	history     []history.Entry
+	recentPlays int
+	recentHours int
+	random      Random
+	mode        Mode
+}
+
+// recent is the files played in the last recentPlays plays or recentHours hours.
+func (s *notRecentlyPlayedSelector) recent() map[string]bool {
+	recent := map[string]bool{}
+	since := Now().Add(-time.Duration(s.recentHours) * time.Hour)
+	for i, entry := range s.history {
+		if len(s.history)-i <= s.recentPlays || (s.recentHours > 0 && entry.Time.After(since)) {
+			recent[entry.File] = true
+		}
+	}
+	return recent
+}
+
+func (s *notRecentlyPlayedSelector) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	recent := s.recent()
+	fresh := []Candidate{}
+	for _, candidate := range candidates {
+		if !recent[candidate.File] {
+			fresh = append(fresh, candidate)
+		}
+	}
+	if len(fresh) == 0 {
+		return leastRecentlyPlayed(candidates, s.history, s.random), nil
+	}
+	weighted := &weightedSelector{random: s.random, mode: s.mode}
+	return weighted.Select(fresh)
+}
@@ -0,0 +1,43 @@
+package selection
+
+import (
+	"sort"
+
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+type roundRobinState struct {
+	Last string `json:"last,omitempty"`
+}
+
+// roundRobinSelector plays the files in name order, carrying on after the last file it played even when files
+// were added or removed since.
+type roundRobinSelector struct {
+	libraryBase string
+}
+
+func (s *roundRobinSelector) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	path, err := statePath(RoundRobinStrategy, s.libraryBase, candidates)
+	if err != nil {
+		return "", err
+	}
+	sorted := files(candidates)
+	saved := roundRobinState{}
+	// state that can't be read starts over
+	state.Load(path, &saved)
+	next := sorted[0]
+	if saved.Last != "" {
+		i := sort.SearchStrings(sorted, saved.Last)
+		if i < len(sorted) && sorted[i] == saved.Last {
+			i++
+		}
+		if i < len(sorted) {
+			next = sorted[i]
+		}
+	}
+	return next, state.Save(path, roundRobinState{Last: next})
+}
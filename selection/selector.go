@@ -0,0 +1,249 @@
+package selection
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/history"
+)
+
+const (
+	// RandomStrategy picks any file, every file as likely as the others.
+	RandomStrategy = "random"
+	// WeightedStrategy picks using the weights of the libraries and the selection mode.
+	WeightedStrategy = "weighted"
+	// ShuffleBagStrategy plays every file once before repeating any, see ShuffleBag.
+	ShuffleBagStrategy = "shuffle-bag"
+	// RoundRobinStrategy plays the files in order, starting over after the last one.
+	RoundRobinStrategy = "round-robin"
+	// LeastRecentlyPlayedStrategy plays the file that has gone longest without being played.
+	LeastRecentlyPlayedStrategy = "least-recently-played"
+	// NotRecentlyPlayedStrategy picks from the files that weren't played in the last few plays or hours.
+	NotRecentlyPlayedStrategy = "not-recently-played"
+	// HashStrategy always picks the same file for the same key, like a commit.
+	HashStrategy = "hash"
+)
+
+// Strategies lists the names of every selection strategy.
+func Strategies() []string {
+	return []string{
+		RandomStrategy,
+		WeightedStrategy,
+		ShuffleBagStrategy,
+		RoundRobinStrategy,
+		LeastRecentlyPlayedStrategy,
+		NotRecentlyPlayedStrategy,
+		HashStrategy,
+	}
+}
+
+// Candidate is a file that can be selected, with the library it is in and that library's weight.
+type Candidate struct {
+	File    string
+	Library string
+	Weight  int
+	// FileWeight is how likely the file is compared to the others in its library, files without one all count as
+	// 1.  Only the strategies in UsesFileWeights use it.
+	FileWeight int
+}
+
+func (c Candidate) fileWeight() int {
+	if c.FileWeight <= 0 {
+		return 1
+	}
+	return c.FileWeight
+}
+
+// pickFile chooses one of the candidates using their file weights.
+func pickFile(candidates []Candidate, random Random) string {
+	weights := []int{}
+	for _, candidate := range candidates {
+		weights = append(weights, candidate.fileWeight())
+	}
+	return candidates[WeightedIndex(weights, random.Intn)].File
+}
+
+// Selector chooses which of the candidates to play.  Candidates from libraries with a weight of 0 are never
+// chosen.
+type Selector interface {
+	Select(candidates []Candidate) (string, error)
+}
+
+// Random is where a selector gets its randomness, *rand.Rand satisfies it.
+type Random interface {
+	Intn(n int) int
+}
+
+// RandomFunc uses a function like rand.Intn as a Random.
+type RandomFunc func(n int) int
+
+func (f RandomFunc) Intn(n int) int {
+	return f(n)
+}
+
+// NewRandom returns a random source seeded from seed, so the same seed always makes the same choices.  Without a
+// seed every random source is different.
+func NewRandom(seed string) *rand.Rand {
+	if seed == "" {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+	return rand.New(rand.NewSource(int64(hash.Sum64())))
+}
+
+// Options are what the strategies need beyond the candidates.
+type Options struct {
+	Random Random
+	// Mode is how the weighted strategy uses weights.
+	Mode Mode
+	// LibraryBase keeps the saved state of strategies for different library bases apart.
+	LibraryBase string
+	// HashKey is what the hash strategy picks a file for.
+	HashKey string
+	// History is the sounds played so far, oldest first, for the strategies that go by what was played.
+	History []history.Entry
+	// RecentPlays and RecentHours are how far back the not-recently-played strategy avoids files.
+	RecentPlays int
+	RecentHours int
+}
+
+// UsesHistory is true for the strategies that need the play history.
+func UsesHistory(strategy string) bool {
+	return strategy == LeastRecentlyPlayedStrategy || strategy == NotRecentlyPlayedStrategy
+}
+
+// UsesFileWeights is true for the strategies that make files with a bigger file weight more likely, the others
+// ignore ratings and favorites.
+func UsesFileWeights(strategy string) bool {
+	return strategy == RandomStrategy || strategy == WeightedStrategy || strategy == NotRecentlyPlayedStrategy
+}
+
+// New returns the selector for a strategy.
+func New(strategy string, options Options) (Selector, error) {
+	switch strategy {
+	case RandomStrategy:
+		return &randomSelector{random: options.Random}, nil
+	case WeightedStrategy:
+		return &weightedSelector{random: options.Random, mode: options.Mode}, nil
+	case ShuffleBagStrategy:
+		return &ShuffleBag{LibraryBase: options.LibraryBase, Random: options.Random}, nil
+	case RoundRobinStrategy:
+		return &roundRobinSelector{libraryBase: options.LibraryBase}, nil
+	case LeastRecentlyPlayedStrategy:
+		return &leastRecentlyPlayedSelector{history: options.History, random: options.Random}, nil
+	case NotRecentlyPlayedStrategy:
+		return &notRecentlyPlayedSelector{
+			history:     options.History,
+			recentPlays: options.RecentPlays,
+			recentHours: options.RecentHours,
+			random:      options.Random,
+			mode:        options.Mode,
+		}, nil
+	case HashStrategy:
+		return &hashSelector{key: options.HashKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown strategy '%s', expected one of %s", strategy, strings.Join(Strategies(), ", "))
+	}
+}
+
+// playable drops the candidates from libraries with no weight, and returns an error if none are left.
+func playable(candidates []Candidate) ([]Candidate, error) {
+	result := []Candidate{}
+	for _, candidate := range candidates {
+		if candidate.Weight > 0 {
+			result = append(result, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return result, fmt.Errorf("no files available")
+	}
+	if len(result) == 0 {
+		return result, fmt.Errorf("no files available in libraries with a weight above 0")
+	}
+	return result, nil
+}
+
+// files returns the files of the candidates in a stable order.
+func files(candidates []Candidate) []string {
+	result := []string{}
+	for _, candidate := range candidates {
+		result = append(result, candidate.File)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func libraryNames(candidates []Candidate) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if !seen[candidate.Library] {
+			seen[candidate.Library] = true
+			names = append(names, candidate.Library)
+		}
+	}
+	return names
+}
+
+type randomSelector struct {
+	random Random
+}
+
+func (s *randomSelector) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	return pickFile(candidates, s.random), nil
+}
+
+type weightedSelector struct {
+	random Random
+	mode   Mode
+}
+
+func (s *weightedSelector) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	names := libraryNames(candidates)
+	byLibrary := map[string][]Candidate{}
+	weights := map[string]int{}
+	fileWeights := map[string]int{}
+	for _, candidate := range candidates {
+		byLibrary[candidate.Library] = append(byLibrary[candidate.Library], candidate)
+		weights[candidate.Library] = candidate.Weight
+		fileWeights[candidate.Library] += candidate.fileWeight()
+	}
+	libraryWeights := []int{}
+	for _, name := range names {
+		if s.mode == ByFile {
+			libraryWeights = append(libraryWeights, weights[name]*fileWeights[name])
+		} else {
+			libraryWeights = append(libraryWeights, weights[name])
+		}
+	}
+	// with the library chosen, files are picked by their file weights in both modes
+	return pickFile(byLibrary[names[WeightedIndex(libraryWeights, s.random.Intn)]], s.random), nil
+}
+
+type hashSelector struct {
+	key string
+}
+
+func (s *hashSelector) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	sorted := files(candidates)
+	hash := fnv.New64a()
+	hash.Write([]byte(s.key))
+	return sorted[hash.Sum64()%uint64(len(sorted))], nil
+}
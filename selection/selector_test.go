@@ -0,0 +1,248 @@
+package selection
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/history"
+)
+
+// lowest makes a selector always take the first of its random choices.
+var lowest = RandomFunc(func(n int) int { return 0 })
+
+func TestNewUnknownStrategy(t *testing.T) {
+	if _, err := New("bogus", Options{}); err == nil {
+		t.Errorf("An unknown strategy should return an error")
+	}
+	for _, strategy := range Strategies() {
+		if _, err := New(strategy, Options{Random: lowest}); err != nil {
+			t.Errorf("Strategy %s should have a selector: %s", strategy, err.Error())
+		}
+	}
+}
+
+func TestSelectorsSkipUnweightedLibraries(t *testing.T) {
+	defer tempCache(t)()
+	files := []Candidate{{File: "muted", Library: "muted", Weight: 0}, {File: "played", Library: "default", Weight: 1}}
+	for _, strategy := range Strategies() {
+		selector, _ := New(strategy, Options{Random: rand.New(rand.NewSource(1))})
+		for i := 0; i < 3; i++ {
+			file, err := selector.Select(files)
+			if err != nil || file != "played" {
+				t.Errorf("Strategy %s should only select from weighted libraries, selected %s (err: %v)", strategy, file, err)
+			}
+		}
+		if _, err := selector.Select(files[:1]); err == nil {
+			t.Errorf("Strategy %s should return an error when no library has a weight", strategy)
+		}
+		if _, err := selector.Select([]Candidate{}); err == nil {
+			t.Errorf("Strategy %s should return an error without any candidates", strategy)
+		}
+	}
+}
+
+func TestWeightedSelector(t *testing.T) {
+	files := []Candidate{
+		{File: "a1", Library: "a", Weight: 1},
+		{File: "a2", Library: "a", Weight: 1},
+		{File: "b1", Library: "b", Weight: 3},
+	}
+	cases := []struct {
+		Mode     Mode
+		Pick     int
+		Expected string
+	}{
+		// by library the weights are 1 and 3, by file they are 2 and 3
+		{ByLibrary, 0, "a1"},
+		{ByLibrary, 1, "b1"},
+		{ByFile, 1, "a1"},
+		{ByFile, 2, "b1"},
+	}
+	for _, c := range cases {
+		// the pick chooses the library, then the first file in it is taken
+		pick := c.Pick
+		random := RandomFunc(func(n int) int {
+			result := pick
+			pick = 0
+			return result
+		})
+		selector, _ := New(WeightedStrategy, Options{Random: random, Mode: c.Mode})
+		file, err := selector.Select(files)
+		if err != nil || file != c.Expected {
+			t.Errorf("Weighted selection by %s picking %d should select %s, selected %s (err: %v)", c.Mode.Name(), c.Pick, c.Expected, file, err)
+		}
+	}
+}
+
+func TestSelectorsUseFileWeights(t *testing.T) {
+	files := []Candidate{
+		{File: "a1", Library: "a", Weight: 1, FileWeight: 1},
+		{File: "a2", Library: "a", Weight: 1, FileWeight: 3},
+		{File: "b1", Library: "b", Weight: 1, FileWeight: 2},
+	}
+	cases := []struct {
+		Strategy string
+		Mode     Mode
+		Picks    []int
+		Expected string
+	}{
+		{RandomStrategy, ByLibrary, []int{0}, "a1"},
+		{RandomStrategy, ByLibrary, []int{1}, "a2"},
+		{RandomStrategy, ByLibrary, []int{4}, "b1"},
+		// by library a and b are as likely, then a2 is three times as likely as a1
+		{WeightedStrategy, ByLibrary, []int{0, 1}, "a2"},
+		// by file a has a weight of 4 and b of 2
+		{WeightedStrategy, ByFile, []int{3, 0}, "a1"},
+		{WeightedStrategy, ByFile, []int{4, 1}, "b1"},
+	}
+	for _, c := range cases {
+		picks := append([]int{}, c.Picks...)
+		random := RandomFunc(func(n int) int {
+			pick := picks[0]
+			picks = picks[1:]
+			return pick
+		})
+		selector, _ := New(c.Strategy, Options{Random: random, Mode: c.Mode})
+		if file, _ := selector.Select(files); file != c.Expected {
+			t.Errorf("Strategy %s by %s picking %v should select %s, selected %s", c.Strategy, c.Mode.Name(), c.Picks, c.Expected, file)
+		}
+	}
+}
+
+func TestHashSelector(t *testing.T) {
+	files := candidates("a", "b", "c", "d", "e")
+	selected := map[string]bool{}
+	for _, key := range []string{"1111111", "2222222", "3333333", "4444444", "5555555", "6666666"} {
+		first, _ := (&hashSelector{key: key}).Select(files)
+		// the order of the candidates doesn't matter
+		second, _ := (&hashSelector{key: key}).Select(candidates("e", "d", "c", "b", "a"))
+		if first != second {
+			t.Errorf("The same key should always select the same file, selected %s and %s", first, second)
+		}
+		selected[first] = true
+	}
+	if len(selected) < 2 {
+		t.Errorf("Different keys should select different files, selected: %v", selected)
+	}
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	defer tempCache(t)()
+	selector, _ := New(RoundRobinStrategy, Options{LibraryBase: "/base"})
+	selected := []string{}
+	for i := 0; i < 4; i++ {
+		file, _ := selector.Select(candidates("c", "a", "b"))
+		selected = append(selected, file)
+	}
+	// a file being removed carries on from where it would have been
+	file, _ := selector.Select(candidates("a", "c"))
+	selected = append(selected, file)
+	expected := []string{"a", "b", "c", "a", "c"}
+	for i := range expected {
+		if selected[i] != expected[i] {
+			t.Fatalf("Round robin should select %v, selected %v", expected, selected)
+		}
+	}
+}
+
+// played is a history with the files played a minute apart, ending at now.
+func played(now time.Time, files ...string) []history.Entry {
+	entries := []history.Entry{}
+	for i, file := range files {
+		entries = append(entries, history.Entry{File: file, Time: now.Add(time.Duration(i-len(files)+1) * time.Minute)})
+	}
+	return entries
+}
+
+func TestLeastRecentlyPlayedSelector(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		History  []history.Entry
+		Expected string
+	}{
+		{played(now), "a"},
+		// b and c have never been played, so one of them goes first
+		{played(now, "a"), "b"},
+		{played(now, "a", "b"), "c"},
+		{played(now, "c", "a", "b"), "c"},
+		{played(now, "a", "b", "c", "a"), "b"},
+	}
+	for _, c := range cases {
+		selector, _ := New(LeastRecentlyPlayedStrategy, Options{Random: lowest, History: c.History})
+		if file, _ := selector.Select(candidates("a", "b", "c")); file != c.Expected {
+			t.Errorf("After playing %v least recently played should select %s, selected %s", c.History, c.Expected, file)
+		}
+	}
+}
+
+func TestNotRecentlyPlayedSelector(t *testing.T) {
+	origNow := Now
+	defer func() {
+		Now = origNow
+	}()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	Now = func() time.Time {
+		return now
+	}
+	cases := []struct {
+		History     []history.Entry
+		RecentPlays int
+		RecentHours int
+		Expected    string
+	}{
+		{played(now, "a", "b"), 0, 0, "a"},
+		{played(now, "a", "b"), 1, 0, "a"},
+		{played(now, "a", "b"), 2, 0, "c"},
+		{played(now, "a", "b"), 0, 1, "c"},
+		{played(now.Add(-2*time.Hour), "a", "b"), 0, 1, "a"},
+		// everything was played recently, so the one played longest ago plays
+		{played(now, "c", "a", "b"), 3, 0, "c"},
+	}
+	for _, c := range cases {
+		selector, _ := New(NotRecentlyPlayedStrategy, Options{
+			Random:      lowest,
+			History:     c.History,
+			RecentPlays: c.RecentPlays,
+			RecentHours: c.RecentHours,
+		})
+		if file, _ := selector.Select(candidates("a", "b", "c")); file != c.Expected {
+			t.Errorf("After playing %v avoiding %d plays and %d hours should select %s, selected %s", c.History, c.RecentPlays, c.RecentHours, c.Expected, file)
+		}
+	}
+}
+
+func TestStatePathPerLibrarySet(t *testing.T) {
+	defer tempCache(t)()
+	both := []Candidate{{File: "a", Library: "memes"}, {File: "b", Library: "default"}}
+	reversed := []Candidate{{File: "b", Library: "default"}, {File: "a", Library: "memes"}}
+	one := []Candidate{{File: "b", Library: "default"}}
+	bothPath, _ := statePath(ShuffleBagStrategy, "/base", both)
+	reversedPath, _ := statePath(ShuffleBagStrategy, "/base", reversed)
+	onePath, _ := statePath(ShuffleBagStrategy, "/base", one)
+	if bothPath != reversedPath {
+		t.Errorf("The order of libraries should not change the state, was %s and %s", bothPath, reversedPath)
+	}
+	if onePath == bothPath || filepath.Dir(onePath) != filepath.Dir(bothPath) {
+		t.Errorf("Each set of libraries should have its own state in the same directory, was %s and %s", bothPath, onePath)
+	}
+}
+
+func TestNewRandomSeeded(t *testing.T) {
+	first := NewRandom("abc123")
+	second := NewRandom("abc123")
+	other := NewRandom("def456")
+	same, different := true, false
+	for i := 0; i < 10; i++ {
+		a, b, c := first.Intn(1000), second.Intn(1000), other.Intn(1000)
+		same = same && a == b
+		different = different || a != c
+	}
+	if !same {
+		t.Errorf("Random sources with the same seed should make the same choices")
+	}
+	if !different {
+		t.Errorf("Random sources with different seeds should make different choices")
+	}
+}
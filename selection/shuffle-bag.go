@@ -0,0 +1,81 @@
+package selection
+
+import "github.com/jasoncorbett/push-sounds/state"
+
+// ShuffleBag plays every file once, in a random order, before any file is repeated.  Its state is kept in the
+// user cache directory so it carries over between pushes, with a bag for each set of libraries.
+type ShuffleBag struct {
+	LibraryBase string
+	Random      Random
+}
+
+type bagState struct {
+	// Files are the files the bag was last filled from, used to notice files added to the libraries.
+	Files     []string `json:"files"`
+	Remaining []string `json:"remaining"`
+	Last      string   `json:"last,omitempty"`
+}
+
+// Select takes the next file out of the bag, refilling it once every file has been played.  Files that were
+// removed from the libraries are dropped from the bag, and new files are added to it.
+func (b *ShuffleBag) Select(candidates []Candidate) (string, error) {
+	candidates, err := playable(candidates)
+	if err != nil {
+		return "", err
+	}
+	path, err := statePath(ShuffleBagStrategy, b.LibraryBase, candidates)
+	if err != nil {
+		return "", err
+	}
+	files := files(candidates)
+	saved := bagState{}
+	// state that can't be read starts over
+	state.Load(path, &saved)
+	remaining := b.reconcile(saved, files)
+	if len(remaining) == 0 {
+		remaining = b.shuffled(files)
+		// don't start the new round with the file that ended the last one
+		if len(remaining) > 1 && remaining[0] == saved.Last {
+			swap := 1 + b.Random.Intn(len(remaining)-1)
+			remaining[0], remaining[swap] = remaining[swap], remaining[0]
+		}
+	}
+	next := remaining[0]
+	err = state.Save(path, bagState{Files: files, Remaining: remaining[1:], Last: next})
+	return next, err
+}
+
+// reconcile brings the remaining files up to date with the files in the libraries now.
+func (b *ShuffleBag) reconcile(saved bagState, files []string) []string {
+	current := map[string]bool{}
+	for _, file := range files {
+		current[file] = true
+	}
+	known := map[string]bool{}
+	for _, file := range saved.Files {
+		known[file] = true
+	}
+	remaining := []string{}
+	for _, file := range saved.Remaining {
+		if current[file] {
+			remaining = append(remaining, file)
+		}
+	}
+	for _, file := range files {
+		if !known[file] {
+			// new files go in at a random place so they aren't always played next
+			at := b.Random.Intn(len(remaining) + 1)
+			remaining = append(remaining[:at], append([]string{file}, remaining[at:]...)...)
+		}
+	}
+	return remaining
+}
+
+func (b *ShuffleBag) shuffled(files []string) []string {
+	result := append([]string{}, files...)
+	for i := len(result) - 1; i > 0; i-- {
+		j := b.Random.Intn(i + 1)
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
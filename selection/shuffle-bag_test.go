@@ -0,0 +1,83 @@
+package selection
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// tempCache points the cache directory at a temporary directory.
+func tempCache(t *testing.T) func() {
+	dir, err := os.MkdirTemp("", "temp-cache-*")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err.Error())
+	}
+	orig := GetUserCacheBase
+	GetUserCacheBase = func() (string, error) {
+		return dir, nil
+	}
+	return func() {
+		GetUserCacheBase = orig
+		os.RemoveAll(dir)
+	}
+}
+
+// candidates puts the files in one library with a weight of 1.
+func candidates(files ...string) []Candidate {
+	result := []Candidate{}
+	for _, file := range files {
+		result = append(result, Candidate{File: file, Library: "default", Weight: 1})
+	}
+	return result
+}
+
+func TestShuffleBagPlaysEveryFileBeforeRepeating(t *testing.T) {
+	defer tempCache(t)()
+	bag := &ShuffleBag{LibraryBase: "/base", Random: rand.New(rand.NewSource(1))}
+	files := candidates("a", "b", "c", "d")
+
+	for round := 0; round < 3; round++ {
+		seen := map[string]bool{}
+		for range files {
+			file, err := bag.Select(files)
+			if err != nil {
+				t.Fatalf("Error selecting from shuffle bag: %s", err.Error())
+			}
+			if seen[file] {
+				t.Fatalf("Round %d repeated %s before playing every file", round, file)
+			}
+			seen[file] = true
+		}
+	}
+}
+
+func TestShuffleBagReconcilesLibraryChanges(t *testing.T) {
+	defer tempCache(t)()
+	bag := &ShuffleBag{LibraryBase: "/base", Random: rand.New(rand.NewSource(1))}
+
+	first, _ := bag.Select(candidates("a", "b", "c"))
+	// one of the files left in the bag is removed, and a new file is added part way through the round
+	left := []string{}
+	for _, file := range []string{"a", "b", "c"} {
+		if file != first {
+			left = append(left, file)
+		}
+	}
+	removed := left[0]
+	files := candidates(left[1], "d")
+
+	seen := map[string]bool{}
+	for range files {
+		file, err := bag.Select(files)
+		if err != nil {
+			t.Fatalf("Error selecting from shuffle bag: %s", err.Error())
+		}
+		if file == removed || file == first || seen[file] {
+			t.Errorf("A removed or already played file was selected: %s", file)
+		}
+		seen[file] = true
+	}
+	if !seen["d"] {
+		t.Errorf("A file added to the libraries should be selected in the same round, selected: %v", seen)
+	}
+}
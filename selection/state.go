@@ -0,0 +1,28 @@
+package selection
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	GetUserCacheBase = os.UserCacheDir
+)
+
+// statePath is where a strategy keeps its state for the libraries the candidates are in, each set of libraries
+// has its own state.
+func statePath(strategy string, libraryBase string, candidates []Candidate) (string, error) {
+	cacheDir, err := GetUserCacheBase()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the cache directory for the %s strategy: %s", strategy, err.Error())
+	}
+	names := libraryNames(candidates)
+	sort.Strings(names)
+	sum := sha256.Sum256([]byte(libraryBase + "\n" + strings.Join(names, "\n")))
+	return filepath.Join(cacheDir, "push-sounds", strategy, hex.EncodeToString(sum[:8])+".json"), nil
+}
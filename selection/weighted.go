@@ -0,0 +1,91 @@
+package selection
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ByLibrary picks a library by its weight, then a file within it.
+	ByLibrary Mode = iota
+	// ByFile pools the files from every library, each file weighted by its library's weight.
+	ByFile
+)
+
+// Mode is how a file is chosen from several libraries.
+type Mode int
+
+func (m Mode) Name() string {
+	switch m {
+	case ByLibrary:
+		return "library"
+	case ByFile:
+		return "file"
+	default:
+		return "unknown"
+	}
+}
+
+func Modes() []Mode {
+	return []Mode{ByLibrary, ByFile}
+}
+
+func ParseMode(name string) (Mode, error) {
+	for _, mode := range Modes() {
+		if mode.Name() == name {
+			return mode, nil
+		}
+	}
+	return ByLibrary, fmt.Errorf("unknown selection mode '%s', expected library or file", name)
+}
+
+// WeightedLibrary is a library and how often it should be chosen compared to the others.
+type WeightedLibrary struct {
+	Name   string
+	Weight int
+}
+
+// ParseLibraries reads libraries written as name or name:weight, a library without a weight has a weight of 1.
+// Values can also hold several libraries separated by commas.
+func ParseLibraries(values []string) ([]WeightedLibrary, error) {
+	libraries := []WeightedLibrary{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			library := WeightedLibrary{Name: part, Weight: 1}
+			if i := strings.LastIndex(part, ":"); i >= 0 {
+				weight, err := strconv.Atoi(part[i+1:])
+				if err != nil || weight < 0 {
+					return libraries, fmt.Errorf("invalid weight for library %s, expected a whole number of 0 or more", part)
+				}
+				library = WeightedLibrary{Name: part[:i], Weight: weight}
+			}
+			libraries = append(libraries, library)
+		}
+	}
+	return libraries, nil
+}
+
+// WeightedIndex picks an index at random using intn, in proportion to the weights.  It returns -1 when all of
+// the weights are 0.
+func WeightedIndex(weights []int, intn func(int) int) int {
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total <= 0 {
+		return -1
+	}
+	pick := intn(total)
+	for i, weight := range weights {
+		if pick < weight {
+			return i
+		}
+		pick -= weight
+	}
+	return -1
+}
@@ -0,0 +1,49 @@
+package selection
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParseLibraries(t *testing.T) {
+	actual, err := ParseLibraries([]string{"default:1,memes:3", "classics", "muted:0"})
+	if err != nil {
+		t.Fatalf("Error parsing libraries: %s", err.Error())
+	}
+	expected := []WeightedLibrary{{"default", 1}, {"memes", 3}, {"classics", 1}, {"muted", 0}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %#v, was %#v", expected, actual)
+	}
+	for _, invalid := range []string{"memes:lots", "memes:-1", "memes:"} {
+		if _, err := ParseLibraries([]string{invalid}); err == nil {
+			t.Errorf("Parsing '%s' should have returned an error", invalid)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, mode := range Modes() {
+		parsed, err := ParseMode(mode.Name())
+		if err != nil || parsed != mode {
+			t.Errorf("Selection mode %s should parse to itself, was %s (err: %v)", mode.Name(), parsed.Name(), err)
+		}
+	}
+	if _, err := ParseMode("bogus"); err == nil {
+		t.Errorf("Parsing an unknown selection mode should return an error")
+	}
+}
+
+func TestWeightedIndexFollowsWeights(t *testing.T) {
+	counts := make([]int, 2)
+	for i := 0; i < 4000; i++ {
+		counts[WeightedIndex([]int{1, 3}, rand.Intn)]++
+	}
+	// the second index should be picked about three times as often as the first
+	if counts[1] < counts[0]*2 {
+		t.Errorf("Weights of 1 and 3 should favor the second index, counts were %v", counts)
+	}
+	if WeightedIndex([]int{0, 0}, rand.Intn) != -1 {
+		t.Errorf("Weights that are all 0 should not pick an index")
+	}
+}
@@ -1,9 +1,19 @@
 package sound
 
+import "time"
+
 type AudioFileType int
 
 type Sound interface {
 	Play() error
+	// Stop ends a sound that is playing, Play returns once it has stopped.
+	Stop()
+	// SetVolume changes how loud the sound plays, as a percentage of how it was recorded.
+	SetVolume(percent int)
+	// SetMaxDuration stops the sound with a short fade-out once it has played for max.
+	SetMaxDuration(max time.Duration)
+	// Duration is how long the sound takes to play.
+	Duration() time.Duration
 	Type() AudioFileType
 	Location() string
 }
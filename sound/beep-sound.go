@@ -2,11 +2,14 @@ package sound
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
 	"github.com/faiface/beep/flac"
 	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/speaker"
@@ -14,6 +17,11 @@ import (
 	"github.com/faiface/beep/wav"
 )
 
+const (
+	// a sound cut short by its max duration fades out over this long
+	fadeOutLength = 500 * time.Millisecond
+)
+
 var (
 	NewFromFile = newFromFile
 )
@@ -22,6 +30,11 @@ type beepSound struct {
 	Path   string
 	stream beep.StreamSeekCloser
 	format beep.Format
+	volume int
+	// maxDuration is how long the sound plays for at most, it plays to the end when it is zero.
+	maxDuration time.Duration
+	stop        chan struct{}
+	once        sync.Once
 }
 
 func newFromFile(soundFile string) (Sound, error) {
@@ -52,6 +65,8 @@ func newFromFile(soundFile string) (Sound, error) {
 		Path:   soundFile,
 		stream: stream,
 		format: format,
+		volume: 100,
+		stop:   make(chan struct{}),
 	}, nil
 
 }
@@ -61,16 +76,100 @@ func (bs *beepSound) Play() error {
 	if err != nil {
 		return fmt.Errorf("unable to initialize audio: %s", err.Error())
 	}
-	done := make(chan bool)
-	speaker.Play(beep.Seq(bs.stream, beep.Callback(func() {
+	done := make(chan bool, 1)
+	speaker.Play(beep.Seq(bs.streamer(), beep.Callback(func() {
 		done <- true
 	})))
 
-	<-done
+	select {
+	case <-done:
+	case <-bs.stop:
+		speaker.Clear()
+	}
 	time.Sleep(time.Second / 10)
 	return nil
 }
 
+func (bs *beepSound) Stop() {
+	bs.once.Do(func() {
+		if bs.stop != nil {
+			close(bs.stop)
+		}
+	})
+}
+
+func (bs *beepSound) SetVolume(percent int) {
+	bs.volume = percent
+}
+
+func (bs *beepSound) SetMaxDuration(max time.Duration) {
+	bs.maxDuration = max
+}
+
+func (bs *beepSound) Duration() time.Duration {
+	duration := bs.format.SampleRate.D(bs.stream.Len())
+	if bs.maxDuration > 0 && duration > bs.maxDuration {
+		return bs.maxDuration
+	}
+	return duration
+}
+
+// streamer is the sound's stream adjusted to its volume, and cut short with a fade-out at its max duration.
+func (bs *beepSound) streamer() beep.Streamer {
+	var streamer beep.Streamer = bs.stream
+	if bs.volume != 100 {
+		streamer = &effects.Volume{
+			Streamer: streamer,
+			Base:     2,
+			Volume:   math.Log2(float64(bs.volume) / 100),
+			Silent:   bs.volume <= 0,
+		}
+	}
+	if bs.maxDuration > 0 && bs.format.SampleRate.D(bs.stream.Len()) > bs.maxDuration {
+		fade := fadeOutLength
+		if fade > bs.maxDuration {
+			fade = bs.maxDuration
+		}
+		streamer = &fadeOut{
+			Streamer: streamer,
+			Length:   bs.format.SampleRate.N(bs.maxDuration),
+			Fade:     bs.format.SampleRate.N(fade),
+		}
+	}
+	return streamer
+}
+
+// fadeOut plays the first Length samples of a streamer, fading out over the last Fade of them.
+type fadeOut struct {
+	Streamer beep.Streamer
+	Length   int
+	Fade     int
+	position int
+}
+
+func (f *fadeOut) Stream(samples [][2]float64) (int, bool) {
+	if f.position >= f.Length {
+		return 0, false
+	}
+	if len(samples) > f.Length-f.position {
+		samples = samples[:f.Length-f.position]
+	}
+	n, ok := f.Streamer.Stream(samples)
+	for i := 0; i < n; i++ {
+		if left := f.Length - f.position - i; left < f.Fade {
+			gain := float64(left) / float64(f.Fade)
+			samples[i][0] *= gain
+			samples[i][1] *= gain
+		}
+	}
+	f.position += n
+	return n, ok
+}
+
+func (f *fadeOut) Err() error {
+	return f.Streamer.Err()
+}
+
 func (bs *beepSound) Location() string {
 	return bs.Path
 }
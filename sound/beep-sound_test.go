@@ -2,7 +2,12 @@ package sound
 
 import (
 	"fmt"
+	"math"
 	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
 )
 
 func TestBeepSound_PlayInvalid(t *testing.T) {
@@ -30,6 +35,42 @@ func TestBeepSound_Type(t *testing.T) {
 	}
 }
 
+func TestBeepSound_SetVolume(t *testing.T) {
+	sound := beepSound{volume: 100}
+	if _, adjusted := sound.streamer().(*effects.Volume); adjusted {
+		t.Error("A sound at full volume should play its stream unchanged")
+	}
+	sound.SetVolume(50)
+	volume, adjusted := sound.streamer().(*effects.Volume)
+	if !adjusted || volume.Volume != -1 || volume.Silent {
+		t.Errorf("A sound at 50%% volume should be played at half the gain, was: %#v", sound.streamer())
+	}
+	sound.SetVolume(0)
+	volume, adjusted = sound.streamer().(*effects.Volume)
+	if !adjusted || !volume.Silent {
+		t.Errorf("A sound at no volume should be silent, was: %#v", sound.streamer())
+	}
+}
+
+// closer lets a stream from a buffer stand in for a decoded file.
+type closer struct {
+	beep.StreamSeeker
+}
+
+func (closer) Close() error {
+	return nil
+}
+
+func TestBeepSound_Duration(t *testing.T) {
+	format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	buffer := beep.NewBuffer(format)
+	buffer.Append(beep.Silence(66150))
+	sound := beepSound{stream: closer{buffer.Streamer(0, buffer.Len())}, format: format}
+	if sound.Duration() != 1500*time.Millisecond {
+		t.Errorf("A sound of 66150 samples at 44100Hz should last 1.5s, was %s", sound.Duration())
+	}
+}
+
 func TestNewFromFile(t *testing.T) {
 
 }
@@ -37,3 +78,61 @@ func TestNewFromFile(t *testing.T) {
 const (
 	MP3_BASE64 = ""
 )
+
+func TestBeepSound_Stop(t *testing.T) {
+	sound := beepSound{stop: make(chan struct{})}
+	sound.Stop()
+	sound.Stop()
+	select {
+	case <-sound.stop:
+	default:
+		t.Error("Stopping a sound should signal it to stop")
+	}
+}
+
+// constantStream is a stream of samples at full volume.
+type constantStream struct {
+	length   int
+	position int
+}
+
+func (cs *constantStream) Stream(samples [][2]float64) (int, bool) {
+	n := 0
+	for ; n < len(samples) && cs.position < cs.length; n++ {
+		samples[n] = [2]float64{1, 1}
+		cs.position++
+	}
+	return n, n > 0
+}
+
+func (cs *constantStream) Err() error       { return nil }
+func (cs *constantStream) Len() int         { return cs.length }
+func (cs *constantStream) Position() int    { return cs.position }
+func (cs *constantStream) Seek(p int) error { cs.position = p; return nil }
+func (cs *constantStream) Close() error     { return nil }
+
+func TestBeepSound_MaxDuration(t *testing.T) {
+	sound := beepSound{stream: &constantStream{length: 1000}, format: beep.Format{SampleRate: 100}, volume: 100}
+	if sound.Duration() != 10*time.Second {
+		t.Errorf("A sound without a max duration should play to the end, was %s", sound.Duration())
+	}
+	sound.SetMaxDuration(20 * time.Second)
+	if _, faded := sound.streamer().(*fadeOut); faded || sound.Duration() != 10*time.Second {
+		t.Errorf("A sound shorter than its max duration should play to the end, was %s", sound.Duration())
+	}
+	sound.SetMaxDuration(time.Second)
+	if sound.Duration() != time.Second {
+		t.Errorf("A sound longer than its max duration should play for the max duration, was %s", sound.Duration())
+	}
+	samples := make([][2]float64, 200)
+	n, _ := sound.streamer().Stream(samples)
+	if n != 100 {
+		t.Fatalf("A sound cut short at a second should play 100 samples, played %d", n)
+	}
+	// the fade-out is half a second, the last 50 samples
+	for i, expected := range map[int]float64{0: 1, 49: 1, 50: 1, 75: 0.5, 99: 0.02} {
+		if math.Abs(samples[i][0]-expected) > 0.001 {
+			t.Errorf("Expected sample %d to be %f, was %f", i, expected, samples[i][0])
+		}
+	}
+}
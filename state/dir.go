@@ -0,0 +1,32 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	GetBase = base
+)
+
+// base is $XDG_STATE_HOME, or ~/.local/state when it isn't set.
+func base() (string, error) {
+	if state := os.Getenv("XDG_STATE_HOME"); state != "" {
+		return state, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// Dir is where push-sounds keeps what it remembers between pushes, like the history.
+func Dir() (string, error) {
+	stateBase, err := GetBase()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the state directory: %s", err.Error())
+	}
+	return filepath.Join(stateBase, "push-sounds"), nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Load reads state saved with Save into state, a missing file leaves it as it was.
+func Load(path string, state interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil && os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", path, err.Error())
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return fmt.Errorf("unable to read %s: %s", path, err.Error())
+	}
+	return nil
+}
+
+// Save writes state to path as json, creating its directory if needed.
+func Save(path string, state interface{}) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("unable to save %s: %s", path, err.Error())
+	}
+	return WriteFile(path, content)
+}
+
+// WriteFile replaces the file at path with content, creating its directory if needed.  The content is written
+// beside the file then renamed over it, so a reader never sees half of it.
+func WriteFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to save %s: %s", path, err.Error())
+	}
+	temp := fmt.Sprintf("%s.%d", path, os.Getpid())
+	if err := os.WriteFile(temp, content, 0644); err != nil {
+		return fmt.Errorf("unable to save %s: %s", path, err.Error())
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("unable to save %s: %s", path, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockWait = 5 * time.Second
+	// a lock older than this was left by a process that died while holding it
+	staleLock = 30 * time.Second
+)
+
+// Lock takes the lock for path, so pushes in two repositories at the same time take turns writing to it.  The
+// returned func releases it.
+func Lock(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("unable to lock %s: %s", path, err.Error())
+		}
+		if stat, err := os.Stat(lockPath); err == nil && time.Since(stat.ModTime()) > staleLock {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("unable to lock %s: still locked by another push after %s", path, lockWait)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,23 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockRemovesStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locked")
+	os.WriteFile(path+".lock", []byte("1\n"), 0644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(path+".lock", old, old)
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("A stale lock should be taken over: %s", err.Error())
+	}
+	unlock()
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("Unlocking should remove the lock")
+	}
+}
@@ -0,0 +1,26 @@
+// Package statetest keeps what tests remember between pushes out of the real state directory.
+package statetest
+
+import (
+	"testing"
+
+	"github.com/jasoncorbett/push-sounds/state"
+)
+
+// UseDir keeps the state under base until the returned func is called.
+func UseDir(base string) func() {
+	orig := state.GetBase
+	state.GetBase = func() (string, error) {
+		return base, nil
+	}
+	return func() {
+		state.GetBase = orig
+	}
+}
+
+// UseTemp keeps the state under a temporary directory until the test ends, and returns the directory.
+func UseTemp(t testing.TB) string {
+	base := t.TempDir()
+	t.Cleanup(UseDir(base))
+	return base
+}
@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/config"
+	"github.com/jasoncorbett/push-sounds/history"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Now = time.Now
+)
+
+var StatsCommand = &cli.Command{
+	Name:   "stats",
+	Usage:  "Show which sounds have played, where and when, from the play history",
+	Action: showStats,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "only count plays since a date like 2024-01-31, or a time ago like 36h or 7d",
+		},
+		&cli.StringFlag{
+			Name:  "repo",
+			Usage: "only count plays in a repository, by its path or name",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "how to show the stats: table, json or csv",
+			Value: "table",
+		},
+	},
+}
+
+func showStats(c *cli.Context) error {
+	now := Now()
+	var since time.Time
+	if c.IsSet("since") {
+		var err error
+		if since, err = ParseSince(c.String("since"), now); err != nil {
+			return err
+		}
+	}
+	var write func(io.Writer, Stats) error
+	switch c.String("format") {
+	case "table":
+		write = writeTable
+	case "json":
+		write = writeJSON
+	case "csv":
+		write = writeCSV
+	default:
+		return fmt.Errorf("unknown format '%s', expected table, json or csv", c.String("format"))
+	}
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+	return write(c.App.Writer, Compute(Filter(entries, since, c.String("repo")), libraryFiles(c), now))
+}
+
+// libraryFiles lists the files in every library, problems reading the libraries only leave out never played files.
+func libraryFiles(c *cli.Context) map[string][]string {
+	files := map[string][]string{}
+	settings, err := config.LoadSettings(c)
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: %s\n", err.Error())
+	}
+	lib, err := libraries.NewSoundLibrary(settings.String(c, "library-base"))
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: unable to find never played sounds: %s\n", err.Error())
+		return files
+	}
+	names, err := lib.ListLibraries()
+	if err != nil {
+		fmt.Fprintf(c.App.ErrWriter, "push-sounds: unable to find never played sounds: %s\n", err.Error())
+		return files
+	}
+	for _, name := range names {
+		if libraryFiles, err := lib.ListFiles(name); err == nil {
+			files[name] = libraryFiles
+		}
+	}
+	return files
+}
+
+func writeCounts(w io.Writer, title string, counts []Count) {
+	fmt.Fprintf(w, "%-40s %s\n", title, "Plays")
+	fmt.Fprintf(w, "%s %s\n", strings.Repeat("-", 40), strings.Repeat("-", len("Plays")))
+	if len(counts) == 0 {
+		fmt.Fprintln(w, "None")
+	}
+	for _, count := range counts {
+		fmt.Fprintf(w, "%-40s %5d\n", count.Name, count.Plays)
+	}
+	fmt.Fprintln(w)
+}
+
+func writeTable(w io.Writer, stats Stats) error {
+	fmt.Fprintf(w, "%d plays, a streak of %d days\n\n", stats.Plays, stats.Streak)
+	writeCounts(w, "Most played", stats.MostPlayed)
+	writeCounts(w, "Least played", stats.LeastPlayed)
+	fmt.Fprintln(w, "Never played")
+	fmt.Fprintln(w, strings.Repeat("-", 40))
+	if len(stats.NeverPlayed) == 0 {
+		fmt.Fprintln(w, "None")
+	}
+	for _, name := range stats.NeverPlayed {
+		fmt.Fprintln(w, name)
+	}
+	fmt.Fprintln(w)
+	writeCounts(w, "Repository", stats.ByRepo)
+	writeCounts(w, "Day", stats.ByDay)
+	return nil
+}
+
+func writeJSON(w io.Writer, stats Stats) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
+
+// writeCSV writes a row for each number in the stats, with the section it is in.
+func writeCSV(w io.Writer, stats Stats) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"section", "name", "plays"})
+	writer.Write([]string{"total", "plays", strconv.Itoa(stats.Plays)})
+	writer.Write([]string{"total", "streak", strconv.Itoa(stats.Streak)})
+	sections := []struct {
+		Name   string
+		Counts []Count
+	}{
+		{"most-played", stats.MostPlayed},
+		{"least-played", stats.LeastPlayed},
+		{"by-repo", stats.ByRepo},
+		{"by-day", stats.ByDay},
+	}
+	for _, section := range sections {
+		for _, count := range section.Counts {
+			writer.Write([]string{section.Name, count.Name, strconv.Itoa(count.Plays)})
+		}
+	}
+	for _, name := range stats.NeverPlayed {
+		writer.Write([]string{"never-played", name, "0"})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jasoncorbett/push-sounds/history"
+	"github.com/jasoncorbett/push-sounds/libraries"
+	"github.com/jasoncorbett/push-sounds/mock_libraries"
+	"github.com/jasoncorbett/push-sounds/state/statetest"
+	"github.com/urfave/cli/v2"
+)
+
+// runStats runs the stats command with a history of the entries and a library with a file that was never played.
+func runStats(t *testing.T, entries []history.Entry, args ...string) (string, error) {
+	statetest.UseTemp(t)
+	origNow := Now
+	origNsl := libraries.NewSoundLibrary
+	defer func() {
+		Now = origNow
+		libraries.NewSoundLibrary = origNsl
+	}()
+	Now = func() time.Time {
+		return now
+	}
+	for _, entry := range entries {
+		history.Record(entry)
+	}
+	m := gomock.NewController(t)
+	msl := mock_libraries.NewMockSoundLibrary(m)
+	libraries.NewSoundLibrary = func(basePath string) (libraries.SoundLibrary, error) {
+		return msl, nil
+	}
+	msl.EXPECT().ListLibraries().Return([]string{"default"}, nil).AnyTimes()
+	msl.EXPECT().ListFiles("default").Return([]string{"/base/default/a", "/base/default/never"}, nil).AnyTimes()
+
+	var out bytes.Buffer
+	app := &cli.App{
+		Writer:    &out,
+		ErrWriter: &out,
+		Flags: []cli.Flag{
+			&cli.PathFlag{Name: "library-base", Value: "/base"},
+			&cli.PathFlag{Name: "config"},
+		},
+		Commands: []*cli.Command{StatsCommand},
+	}
+	err := app.Run(append([]string{"push-sounds", "stats"}, args...))
+	return out.String(), err
+}
+
+func TestStatsCommandJSON(t *testing.T) {
+	entries := []history.Entry{entry(10, "default", "a", "/src/one"), entry(0, "default", "a", "/src/one")}
+	out, err := runStats(t, entries, "--format", "json", "--since", "7d")
+	if err != nil {
+		t.Fatalf("Stats should not return an error: %s", err.Error())
+	}
+	stats := Stats{}
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("Stats should be valid json: %s\n%s", err.Error(), out)
+	}
+	if stats.Plays != 1 || stats.Streak != 1 || len(stats.NeverPlayed) != 1 || stats.NeverPlayed[0] != "default/never" {
+		t.Errorf("Stats should only count plays since 7 days ago, was %+v", stats)
+	}
+}
+
+func TestStatsCommandCSVAndTable(t *testing.T) {
+	entries := []history.Entry{entry(0, "default", "a", "/src/one")}
+	out, err := runStats(t, entries, "--format", "csv")
+	if err != nil || !strings.HasPrefix(out, "section,name,plays\n") || !strings.Contains(out, "by-repo,/src/one,1\n") || !strings.Contains(out, "never-played,default/never,0\n") {
+		t.Errorf("Stats should be written as csv, was (err: %v):\n%s", err, out)
+	}
+	out, err = runStats(t, entries)
+	if err != nil || !strings.Contains(out, "1 plays, a streak of 1 days") || !strings.Contains(out, "default/a") {
+		t.Errorf("Stats should be written as a table by default, was (err: %v):\n%s", err, out)
+	}
+}
+
+func TestStatsCommandInvalidFlags(t *testing.T) {
+	if _, err := runStats(t, nil, "--format", "xml"); err == nil {
+		t.Errorf("An unknown format should return an error")
+	}
+	if _, err := runStats(t, nil, "--since", "whenever"); err == nil {
+		t.Errorf("An invalid since should return an error")
+	}
+}
@@ -0,0 +1,142 @@
+package stats
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/history"
+)
+
+const (
+	dayFormat = "2006-01-02"
+	// listed is how many sounds are shown as the most and least played
+	listed = 10
+)
+
+// Count is how many times something was played.
+type Count struct {
+	Name  string `json:"name"`
+	Plays int    `json:"plays"`
+}
+
+// Stats summarizes the play history.
+type Stats struct {
+	Plays       int      `json:"plays"`
+	MostPlayed  []Count  `json:"most-played"`
+	LeastPlayed []Count  `json:"least-played"`
+	NeverPlayed []string `json:"never-played"`
+	ByRepo      []Count  `json:"by-repo"`
+	ByDay       []Count  `json:"by-day"`
+	// Streak is how many days in a row ending today, or yesterday when nothing has played yet today, had a play.
+	Streak int `json:"streak"`
+}
+
+// soundName names a sound by its library and file name.
+func soundName(library string, file string) string {
+	return library + "/" + filepath.Base(file)
+}
+
+// ParseSince reads a --since value, either a date like 2024-01-31 or how long ago like 36h or 7d.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if day, err := time.ParseInLocation(dayFormat, value, now.Location()); err == nil {
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("since should be a date like 2024-01-31 or a time ago like 36h or 7d, was '%s'", value)
+}
+
+// Filter keeps the plays since a time, and from a repo when one is given either by its path or its name.
+func Filter(entries []history.Entry, since time.Time, repo string) []history.Entry {
+	filtered := []history.Entry{}
+	for _, entry := range entries {
+		if entry.Time.Before(since) {
+			continue
+		}
+		if repo != "" && filepath.Clean(entry.Repo) != filepath.Clean(repo) && filepath.Base(entry.Repo) != repo {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// Compute summarizes the plays, libraries maps the name of each library to its files for finding the ones that
+// were never played.
+func Compute(entries []history.Entry, libraries map[string][]string, now time.Time) Stats {
+	stats := Stats{Plays: len(entries), NeverPlayed: []string{}}
+	sounds := map[string]int{}
+	repos := map[string]int{}
+	days := map[string]int{}
+	for _, entry := range entries {
+		sounds[soundName(entry.Library, entry.File)]++
+		repo := entry.Repo
+		if repo == "" {
+			repo = "(none)"
+		}
+		repos[repo]++
+		days[entry.Time.In(now.Location()).Format(dayFormat)]++
+	}
+	played := sortedCounts(sounds)
+	sort.SliceStable(played, func(i, j int) bool {
+		return played[i].Plays > played[j].Plays
+	})
+	stats.MostPlayed = limit(played)
+	least := append([]Count{}, played...)
+	sort.SliceStable(least, func(i, j int) bool {
+		return least[i].Plays < least[j].Plays
+	})
+	stats.LeastPlayed = limit(least)
+	names := []string{}
+	for library := range libraries {
+		names = append(names, library)
+	}
+	sort.Strings(names)
+	for _, library := range names {
+		for _, file := range libraries[library] {
+			if name := soundName(library, file); sounds[name] == 0 {
+				stats.NeverPlayed = append(stats.NeverPlayed, name)
+			}
+		}
+	}
+	stats.ByRepo = sortedCounts(repos)
+	stats.ByDay = sortedCounts(days)
+	day := now
+	if days[day.Format(dayFormat)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format(dayFormat)] > 0 {
+		stats.Streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return stats
+}
+
+// sortedCounts turns counts into a list ordered by name.
+func sortedCounts(counts map[string]int) []Count {
+	result := []Count{}
+	for name, plays := range counts {
+		result = append(result, Count{Name: name, Plays: plays})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func limit(counts []Count) []Count {
+	if len(counts) > listed {
+		return counts[:listed]
+	}
+	return counts
+}
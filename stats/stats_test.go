@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jasoncorbett/push-sounds/history"
+)
+
+var now = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func entry(daysAgo int, library string, file string, repo string) history.Entry {
+	return history.Entry{Time: now.AddDate(0, 0, -daysAgo), File: "/base/" + library + "/" + file, Library: library, Repo: repo}
+}
+
+func TestCompute(t *testing.T) {
+	entries := []history.Entry{
+		entry(5, "default", "a.mp3", "/src/one"),
+		entry(1, "default", "a.mp3", "/src/one"),
+		entry(1, "memes", "b.mp3", "/src/two"),
+		entry(0, "default", "a.mp3", "/src/two"),
+	}
+	libraries := map[string][]string{
+		"memes":   {"/base/memes/b.mp3", "/base/memes/c.mp3"},
+		"default": {"/base/default/a.mp3", "/base/default/d.mp3"},
+	}
+	stats := Compute(entries, libraries, now)
+	if stats.Plays != 4 {
+		t.Errorf("There should be 4 plays, was %d", stats.Plays)
+	}
+	if len(stats.MostPlayed) != 2 || stats.MostPlayed[0] != (Count{"default/a.mp3", 3}) {
+		t.Errorf("The most played sound should be default/a.mp3, was %v", stats.MostPlayed)
+	}
+	if len(stats.LeastPlayed) != 2 || stats.LeastPlayed[0] != (Count{"memes/b.mp3", 1}) {
+		t.Errorf("The least played sound should be memes/b.mp3, was %v", stats.LeastPlayed)
+	}
+	if len(stats.NeverPlayed) != 2 || stats.NeverPlayed[0] != "default/d.mp3" || stats.NeverPlayed[1] != "memes/c.mp3" {
+		t.Errorf("Never played sounds should be listed by library, was %v", stats.NeverPlayed)
+	}
+	if len(stats.ByRepo) != 2 || stats.ByRepo[0] != (Count{"/src/one", 2}) || stats.ByRepo[1] != (Count{"/src/two", 2}) {
+		t.Errorf("Plays should be counted by repo, was %v", stats.ByRepo)
+	}
+	if len(stats.ByDay) != 3 || stats.ByDay[0] != (Count{"2024-03-05", 1}) || stats.ByDay[1] != (Count{"2024-03-09", 2}) {
+		t.Errorf("Plays should be counted by day, was %v", stats.ByDay)
+	}
+	if stats.Streak != 2 {
+		t.Errorf("The streak should be 2 days, was %d", stats.Streak)
+	}
+}
+
+func TestComputeStreakCarriesOverFromYesterday(t *testing.T) {
+	entries := []history.Entry{entry(3, "a", "a", ""), entry(2, "a", "a", ""), entry(1, "a", "a", "")}
+	if stats := Compute(entries, nil, now); stats.Streak != 3 {
+		t.Errorf("A streak shouldn't end until a day without plays is over, was %d", stats.Streak)
+	}
+	if stats := Compute(entries[:2], nil, now); stats.Streak != 0 {
+		t.Errorf("A day without plays should end the streak, was %d", stats.Streak)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	cases := []struct {
+		Value    string
+		Expected time.Time
+	}{
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7)},
+	}
+	for _, c := range cases {
+		if actual, err := ParseSince(c.Value, now); err != nil || !actual.Equal(c.Expected) {
+			t.Errorf("Since %s should be %s, was %s (err: %v)", c.Value, c.Expected, actual, err)
+		}
+	}
+	for _, value := range []string{"yesterday", "-3d", "2024-13-01"} {
+		if _, err := ParseSince(value, now); err == nil {
+			t.Errorf("Since %s should return an error", value)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	entries := []history.Entry{
+		entry(5, "default", "a", "/src/one"),
+		entry(1, "default", "a", "/src/one"),
+		entry(1, "default", "a", "/src/two"),
+	}
+	if filtered := Filter(entries, now.AddDate(0, 0, -2), ""); len(filtered) != 2 {
+		t.Errorf("Only plays since the time should be kept, was %v", filtered)
+	}
+	if filtered := Filter(entries, time.Time{}, "/src/one/"); len(filtered) != 2 {
+		t.Errorf("Only plays in the repo path should be kept, was %v", filtered)
+	}
+	if filtered := Filter(entries, now.AddDate(0, 0, -2), "two"); len(filtered) != 1 {
+		t.Errorf("Only plays in the repo name should be kept, was %v", filtered)
+	}
+}